@@ -4,10 +4,10 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 	_ "user-service/api/swagger/public"
 	"user-service/internal/application"
 	"user-service/internal/config"
@@ -18,37 +18,112 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
 	defer cancel()
 
-	// generate config from env vars
-	cfg := config.Get()
+	// build config from the config file, env vars and flags
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if cfg.PrintConfig {
+		if err = cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	// initialize logger
-	optsLogger := logger.LoggerOptions{IsProd: cfg.IsProd}
+	optsLogger := logger.LoggerOptions{IsProd: cfg.IsProd, Level: cfg.LogLevel, Format: cfg.LogFormat, RedactKeys: cfg.LogRedactKeyList()}
 	log := logger.New(optsLogger)
 
-	log.Debug("Get config: ", "", cfg)
+	log.Debug("config loaded", "config", cfg)
 
 	optsApp := application.AppOptions{
-		DB_url:      cfg.DB_URL,
+		DB_url:      cfg.DatabaseURL(),
 		HTTP_port:   cfg.HTTP_port,
 		Timeout:     cfg.Timeout,
 		IdleTimeout: cfg.IdleTimeout,
+		AuthSecret:  cfg.AuthSecret,
+		TokenTTL:    cfg.TokenTTL,
+		MFATokenTTL: cfg.MFATokenTTL,
+		TOTPIssuer:  cfg.TOTPIssuer,
+
+		AdminRequireMFA: cfg.AdminRequireMFA,
+
+		DBMaxConns:          cfg.DBMaxConns,
+		DBMinConns:          cfg.DBMinConns,
+		DBHealthCheckPeriod: cfg.DBHealthCheckPeriod,
+		DBMaxConnLifetime:   cfg.DBMaxConnLifetime,
+		DBMaxConnIdleTime:   cfg.DBMaxConnIdleTime,
+		DBStartupTimeout:    cfg.DBStartupTimeout,
+
+		OIDCIssuer:         cfg.OIDCIssuer,
+		OIDCSigningKeyFile: cfg.OIDCSigningKeyFile,
+		OIDCCodeTTL:        cfg.OIDCCodeTTL,
+		OIDCTokenTTL:       cfg.OIDCTokenTTL,
+
+		AuthMode:            cfg.AuthMode,
+		SessionIdleTTL:      cfg.SessionIdleTTL,
+		SessionMaxLifetime:  cfg.SessionMaxLifetime,
+		SessionCookieSecure: cfg.SessionCookieSecure,
+
+		RateLimits:       cfg.RateLimits,
+		RateLimitBackend: cfg.RateLimitBackend,
+		CORSOrigins:      cfg.CORSOriginList(),
+		TrustedProxies:   cfg.TrustedProxyList(),
+
+		UserInitialStatus:    cfg.UserInitialStatus,
+		DeletedUserRetention: cfg.DeletedUserRetention,
+		UserPurgeInterval:    cfg.UserPurgeInterval,
+
+		UsernameRedirectPeriod: cfg.UsernameRedirectPeriod,
+		UsernameReusePeriod:    cfg.UsernameReusePeriod,
+
+		AvatarDir:       cfg.AvatarDir,
+		AvatarMaxSize:   cfg.AvatarMaxSize,
+		AvatarMaxPixels: cfg.AvatarMaxPixels,
+
+		HealthCheckTimeout: cfg.HealthCheckTimeout,
+		ShutdownDrainDelay: cfg.ShutdownDrainDelay,
+		ShutdownTimeout:    cfg.ShutdownTimeout,
+
+		TracingExporter:    cfg.TracingExporter,
+		OTLPEndpoint:       cfg.OTLPEndpoint,
+		OTLPInsecure:       cfg.OTLPInsecure,
+		TracingSampleRatio: cfg.TracingSampleRatio,
+
+		Reload: application.ReloadOptions{
+			Load:      func() (*config.Config, error) { return config.Load(os.Args[1:]) },
+			Initial:   cfg,
+			WatchFile: cfg.ConfigFile,
+		},
 	}
 	app := application.New(optsApp)
 
-	err := app.Start()
-	if err != nil {
+	if err = app.Start(ctx); err != nil {
 		log.Error("app not started", "desc", err.Error())
+		os.Exit(1)
+	}
+
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+	case err := <-app.Err():
+		log.Error("app failed", "desc", err.Error())
+		exitCode = 1
 	}
-	<-ctx.Done()
 
 	// graceful shutdown
 	log.Info("shutting down...")
-	stopCtx, stopCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer stopCancel()
 
-	err = app.Stop(stopCtx)
-	if err != nil {
+	if err := app.Stop(stopCtx); err != nil {
 		log.Error("app stop error", "desc", err.Error())
+		exitCode = 1
 	}
 	log.Info("app stopped")
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
 }
@@ -0,0 +1,115 @@
+// The blob package stores binary objects, such as avatar images, outside the database.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"user-service/internal/domain/models"
+	"user-service/internal/ports"
+)
+
+// LocalStorage keeps the objects as files under a directory, one file per key. Replicas sharing the objects
+// need a shared volume.
+type LocalStorage struct {
+	dir string
+}
+
+var _ ports.BlobStorage = (*LocalStorage)(nil)
+
+// NewLocal returns the storage keeping the objects under dir, creating the directory if needed.
+func NewLocal(dir string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{dir: dir}, nil
+}
+
+// path returns the file of the key, refusing keys that would escape the directory.
+func (s *LocalStorage) path(key string) (string, error) {
+	if key == "" || path.Clean("/"+key) != "/"+key {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// PutBlob writes the object. The file is replaced atomically, so that readers never see a partial object.
+func (s *LocalStorage) PutBlob(_ context.Context, key string, data []byte) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+// GetBlob opens the object. It returns false if there is no such object.
+func (s *LocalStorage) GetBlob(_ context.Context, key string) (models.Blob, bool, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return models.Blob{}, false, err
+	}
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return models.Blob{}, false, nil
+	}
+	if err != nil {
+		return models.Blob{}, false, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return models.Blob{}, false, err
+	}
+	return models.Blob{Content: f, Size: info.Size(), ModTime: info.ModTime()}, true, nil
+}
+
+// ListBlobs returns the names of the objects and prefixes directly under the prefix.
+func (s *LocalStorage) ListBlobs(_ context.Context, prefix string) ([]string, error) {
+	name, err := s.path(prefix)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), ".tmp-") {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+// DeleteBlobs deletes the objects under the prefix. Deleting missing objects is not an error.
+func (s *LocalStorage) DeleteBlobs(_ context.Context, prefix string) error {
+	name, err := s.path(prefix)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(name)
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"user-service/internal/domain/models"
+)
+
+func (db *DBStorage) SaveAPIKey(ctx context.Context, key models.APIKey) (id int64, err error) {
+	const query = `
+	INSERT INTO api_keys (name, prefix, key_hash, scopes, created_by, expires_at) VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id;
+	`
+	ctx, done := instrument(ctx, "SaveAPIKey", query)
+	defer done(&err)
+	err = db.Pool.QueryRow(ctx, query, key.Name, key.Prefix, key.KeyHash, key.Scopes, key.CreatedBy, key.ExpiresAt).
+		Scan(&id)
+	return
+}
+
+func (db *DBStorage) GetAPIKeyByPrefix(ctx context.Context, prefix string) (key models.APIKey, err error) {
+	const query = `
+	SELECT id, name, prefix, key_hash, scopes, COALESCE(created_by, ''), created_at, expires_at, revoked_at, last_used_at
+	FROM api_keys WHERE prefix = $1;
+	`
+	ctx, done := instrument(ctx, "GetAPIKeyByPrefix", query)
+	defer done(&err)
+	err = db.Pool.QueryRow(ctx, query, prefix).
+		Scan(&key.ID, &key.Name, &key.Prefix, &key.KeyHash, &key.Scopes, &key.CreatedBy, &key.CreatedAt, &key.ExpiresAt, &key.RevokedAt, &key.LastUsedAt)
+	return
+}
+
+func (db *DBStorage) ListAPIKeys(ctx context.Context) (_ []models.APIKey, err error) {
+	const query = `
+	SELECT id, name, prefix, scopes, COALESCE(created_by, ''), created_at, expires_at, revoked_at, last_used_at
+	FROM api_keys ORDER BY id;
+	`
+	ctx, done := instrument(ctx, "ListAPIKeys", query)
+	defer done(&err)
+	rows, err := db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		var key models.APIKey
+		err = rows.Scan(&key.ID, &key.Name, &key.Prefix, &key.Scopes, &key.CreatedBy, &key.CreatedAt, &key.ExpiresAt, &key.RevokedAt, &key.LastUsedAt)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey marks the key as revoked. It reports false if there is no such key.
+func (db *DBStorage) RevokeAPIKey(ctx context.Context, id int64) (_ bool, err error) {
+	const query = `
+	UPDATE api_keys SET revoked_at = COALESCE(revoked_at, now()) WHERE id = $1;
+	`
+	ctx, done := instrument(ctx, "RevokeAPIKey", query)
+	defer done(&err)
+	tag, err := db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// TouchAPIKey records the key usage. The timestamp is updated at most once a minute to avoid
+// a write on every request.
+func (db *DBStorage) TouchAPIKey(ctx context.Context, id int64) (err error) {
+	const query = `
+	UPDATE api_keys SET last_used_at = now()
+	WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - INTERVAL '1 minute');
+	`
+	ctx, done := instrument(ctx, "TouchAPIKey", query)
+	defer done(&err)
+	_, err = db.Pool.Exec(ctx, query, id)
+	return
+}
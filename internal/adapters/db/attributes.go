@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"user-service/internal/domain/models"
+)
+
+// ListAttributeDefinitions returns the definitions of the custom user attributes ordered by name.
+func (db *DBStorage) ListAttributeDefinitions(ctx context.Context) (_ []models.AttributeDefinition, err error) {
+	const query = `
+	SELECT name, description, schema, updated_at FROM user_attribute_definitions ORDER BY name;
+	`
+	ctx, done := instrument(ctx, "ListAttributeDefinitions", query)
+	defer done(&err)
+	rows, err := db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	defs := []models.AttributeDefinition{}
+	for rows.Next() {
+		var def models.AttributeDefinition
+		var schema []byte
+		if err = rows.Scan(&def.Name, &def.Description, &schema, &def.UpdatedAt); err != nil {
+			return nil, err
+		}
+		def.Schema = schema
+		defs = append(defs, def)
+	}
+	return defs, rows.Err()
+}
+
+// SaveAttributeDefinition creates or replaces the definition. Stored values are not checked against a changed
+// schema.
+func (db *DBStorage) SaveAttributeDefinition(ctx context.Context, def models.AttributeDefinition) (_ models.AttributeDefinition, err error) {
+	const query = `
+	INSERT INTO user_attribute_definitions (name, description, schema) VALUES ($1, $2, $3)
+	ON CONFLICT (name) DO UPDATE SET description = EXCLUDED.description, schema = EXCLUDED.schema, updated_at = now()
+	RETURNING updated_at;
+	`
+	ctx, done := instrument(ctx, "SaveAttributeDefinition", query)
+	defer done(&err)
+	err = db.Pool.QueryRow(ctx, query, def.Name, def.Description, string(def.Schema)).Scan(&def.UpdatedAt)
+	return def, err
+}
+
+// DeleteAttributeDefinition removes the definition and the attribute from all users in the same transaction.
+// Each user losing the attribute is recorded in the audit log as updated. It returns false if there is no
+// such definition.
+func (db *DBStorage) DeleteAttributeDefinition(ctx context.Context, name string) (_ bool, err error) {
+	const query = `
+	DELETE FROM user_attribute_definitions WHERE name = $1;
+	`
+	const removeAttribute = `
+	UPDATE users u SET attributes = u.attributes - $1, updated_at = now()
+	FROM (SELECT id, attributes FROM users WHERE attributes ? $1 FOR UPDATE) old
+	WHERE u.id = old.id
+	RETURNING u.id, u.username, old.attributes, u.attributes;
+	`
+	ctx, done := instrument(ctx, "DeleteAttributeDefinition", query)
+	defer done(&err)
+	tx, err := db.begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, query, name)
+	if err != nil || tag.RowsAffected() == 0 {
+		return false, err
+	}
+	rows, err := tx.Query(ctx, removeAttribute, name)
+	if err != nil {
+		return false, err
+	}
+	type change struct{ before, after models.User }
+	var changes []change
+	for rows.Next() {
+		var c change
+		if err = rows.Scan(&c.before.ID, &c.before.Username, &c.before.Attributes, &c.after.Attributes); err != nil {
+			rows.Close()
+			return false, err
+		}
+		c.after.ID, c.after.Username = c.before.ID, c.before.Username
+		changes = append(changes, c)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return false, err
+	}
+	for _, c := range changes {
+		if err = writeAudit(ctx, tx, c.before.ID, c.before.Username, models.AuditActionUpdate, diffUsers(&c.before, &c.after)); err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit(ctx)
+}
+
+// attributesJSON encodes the attributes for a JSONB column, an empty object if there are none.
+func attributesJSON(attrs map[string]any) (string, error) {
+	if len(attrs) == 0 {
+		return "{}", nil
+	}
+	b, err := json.Marshal(attrs)
+	return string(b), err
+}
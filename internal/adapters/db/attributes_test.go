@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"user-service/internal/domain/models"
+)
+
+func TestDeleteAttributeDefinitionAuditsUsers(t *testing.T) {
+	storage := testStorage(t)
+	ctx := context.Background()
+
+	user := testUser("attrs")
+	name := "test_" + user.Username
+	if _, err := storage.SaveAttributeDefinition(ctx, models.AttributeDefinition{Name: name, Schema: []byte(`{"type": "string"}`)}); err != nil {
+		t.Fatal(err)
+	}
+	user.Attributes = map[string]any{name: "engineering"}
+	if err := storage.SaveUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	before, err := storage.GetUser(ctx, user.Username)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found, err := storage.DeleteAttributeDefinition(ctx, name); err != nil || !found {
+		t.Fatalf("DeleteAttributeDefinition() = %t, %v", found, err)
+	}
+	after, err := storage.GetUser(ctx, user.Username)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after.Attributes) != 0 {
+		t.Errorf("attributes = %v, want none", after.Attributes)
+	}
+	if !after.UpdatedAt.After(before.UpdatedAt) {
+		t.Errorf("updated_at = %s, want later than %s", after.UpdatedAt, before.UpdatedAt)
+	}
+	entries, err := storage.ListUserAudit(ctx, user.Username, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 || entries[0].Action != models.AuditActionUpdate || len(entries[0].Changes) != 1 || entries[0].Changes[0].Field != "attributes" {
+		t.Fatalf("last audit entry = %+v, want the update of the attributes", entries)
+	}
+	if c := entries[0].Changes[0]; c.Old == nil || *c.Old != `{"`+name+`":"engineering"}` || c.New == nil || *c.New != "" {
+		t.Errorf("attributes change = %v -> %v", c.Old, c.New)
+	}
+}
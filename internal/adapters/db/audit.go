@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"user-service/internal/domain/models"
+	"user-service/pkg/requestid"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// auditedFields lists the user fields compared in the audit log. The values of secret fields are not recorded.
+var auditedFields = []struct {
+	name   string
+	value  func(u *models.User) string
+	secret bool
+}{
+	{"username", func(u *models.User) string { return u.Username }, false},
+	{"password", func(u *models.User) string { return u.Password }, true},
+	{"first_name", func(u *models.User) string { return u.FirstName }, false},
+	{"last_name", func(u *models.User) string { return u.LastName }, false},
+	{"email", func(u *models.User) string { return u.Email }, false},
+	{"phone", func(u *models.User) string { return u.Phone }, false},
+	{"attributes", func(u *models.User) string {
+		if len(u.Attributes) == 0 {
+			return ""
+		}
+		b, _ := json.Marshal(u.Attributes) // keys are sorted, so equal attributes compare equal
+		return string(b)
+	}, false},
+}
+
+// diffUsers returns the changed fields. A nil before or after means the user is created or deleted.
+// Passwords are stored as salted hashes, so setting a password is always recorded as its change.
+func diffUsers(before, after *models.User) []models.AuditChange {
+	changes := []models.AuditChange{}
+	for _, f := range auditedFields {
+		var from, to *string
+		if before != nil {
+			v := f.value(before)
+			from = &v
+		}
+		if after != nil {
+			v := f.value(after)
+			to = &v
+		}
+		if from != nil && to != nil && *from == *to {
+			continue
+		}
+		if (from == nil || *from == "") && (to == nil || *to == "") {
+			continue
+		}
+		if f.secret {
+			from, to = nil, nil
+		}
+		changes = append(changes, models.AuditChange{Field: f.name, Old: from, New: to})
+	}
+	return changes
+}
+
+// writeAudit appends the entry for the change to the audit log within the transaction making the change.
+// The actor and the request ID are taken from the context.
+func writeAudit(ctx context.Context, tx pgx.Tx, userID, username, action string, changes []models.AuditChange) error {
+	const query = `
+	INSERT INTO user_audit_log (user_id, username, action, actor, request_id, ip, changes) VALUES ($1, $2, $3, $4, $5, $6, $7);
+	`
+	body, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	actor := models.AuditActorFromContext(ctx)
+	_, err = tx.Exec(ctx, query, userID, username, action, actor.Name, requestid.FromContext(ctx), actor.IP, body)
+	return err
+}
+
+// lockUser returns the user with the password hash, locking the row until the end of the transaction.
+func lockUser(ctx context.Context, tx pgx.Tx, username string) (user models.User, err error) {
+	const query = `
+	SELECT id, username, COALESCE(password, ''), COALESCE(first_name, ''), COALESCE(last_name, ''), COALESCE(email, ''), COALESCE(phone, ''), attributes
+	FROM users WHERE username = $1 AND deleted_at IS NULL FOR UPDATE;
+	`
+	err = tx.QueryRow(ctx, query, username).
+		Scan(&user.ID, &user.Username, &user.Password, &user.FirstName, &user.LastName, &user.Email, &user.Phone, &user.Attributes)
+	return
+}
+
+// ListUserAudit returns up to limit audit log entries with ids less than cursor, newest first: the entries
+// of the user having the username, including deleted users and those made under its previous usernames.
+// If no user has the username, the users it was recorded for have been purged: the entries of those
+// users are returned, so the log stays available after the purge. The history of a former holder of a
+// username taken by another user is not merged in. A zero cursor starts from the newest entry.
+func (db *DBStorage) ListUserAudit(ctx context.Context, username string, cursor int64, limit int) (_ []models.AuditEntry, err error) {
+	const query = `
+	WITH owner AS (
+		SELECT id FROM users WHERE username = $1
+	), purged AS (
+		SELECT DISTINCT l.user_id FROM user_audit_log l
+		WHERE l.username = $1 AND l.user_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM owner)
+			AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = l.user_id)
+	)
+	SELECT id, COALESCE(user_id::text, ''), username, action, actor, request_id, ip, changes, created_at
+	FROM user_audit_log
+	WHERE (user_id IN (SELECT id FROM owner UNION ALL SELECT user_id FROM purged)
+		OR (user_id IS NULL AND username = $1 AND NOT EXISTS (SELECT 1 FROM owner)))
+		AND ($2 = 0 OR id < $2)
+	ORDER BY id DESC LIMIT $3;
+	`
+	ctx, done := instrument(ctx, "ListUserAudit", query)
+	defer done(&err)
+	rows, err := db.Pool.Query(ctx, query, username, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var (
+			e       models.AuditEntry
+			changes []byte
+		)
+		if err = rows.Scan(&e.ID, &e.UserID, &e.Username, &e.Action, &e.Actor, &e.RequestID, &e.IP, &changes, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(changes, &e.Changes); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
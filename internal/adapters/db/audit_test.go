@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+	"user-service/internal/domain/models"
+)
+
+// auditActions returns the actions of the audit log of the username, newest first.
+func auditActions(t *testing.T, storage *DBStorage, username string) []string {
+	t.Helper()
+	entries, err := storage.ListUserAudit(context.Background(), username, 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := make([]string, len(entries))
+	for i, e := range entries {
+		actions[i] = e.Action
+	}
+	return actions
+}
+
+func TestListUserAuditAfterPurge(t *testing.T) {
+	storage := testStorage(t)
+	ctx := context.Background()
+
+	user := testUser("audit")
+	if err := storage.SaveUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	updated := user
+	updated.Email = "ivanov@example.com"
+	if err := storage.UpdateUser(ctx, user.Username, updated); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.DeleteUser(ctx, user.Username); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storage.PurgeDeletedUsers(ctx, time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{models.AuditActionPurge, models.AuditActionDelete, models.AuditActionUpdate, models.AuditActionCreate}
+	if got := auditActions(t, storage, user.Username); !slices.Equal(got, want) {
+		t.Fatalf("audit after purge = %v, want %v", got, want)
+	}
+
+	// once another user takes the username, the log of the purged user is no longer merged in
+	next := testUser("audit")
+	next.Username = user.Username
+	if err := storage.SaveUser(ctx, next); err != nil {
+		t.Fatal(err)
+	}
+	if got := auditActions(t, storage, user.Username); !slices.Equal(got, []string{models.AuditActionCreate}) {
+		t.Errorf("audit of the new holder = %v, want [create]", got)
+	}
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"user-service/internal/domain/models"
+	"user-service/pkg/usernames"
+)
+
+// GetCredentials returns the credentials of the user with the username or, if there is none, of the user
+// whose username has the same skeleton, so that users can log in regardless of case.
+func (db *DBStorage) GetCredentials(ctx context.Context, username string) (cred models.Credentials, err error) {
+	const query = `
+	SELECT id, username, COALESCE(password, ''), role, COALESCE(totp_secret, ''), totp_enabled, status
+	FROM users WHERE (username = $1 OR username_norm = $2) AND deleted_at IS NULL
+	ORDER BY username = $1 DESC
+	LIMIT 1;
+	`
+	ctx, done := instrument(ctx, "GetCredentials", query)
+	defer done(&err)
+	err = db.Pool.QueryRow(ctx, query, username, usernames.Skeleton(username)).
+		Scan(&cred.UserID, &cred.Username, &cred.PasswordHash, &cred.Role, &cred.TOTPSecret, &cred.TOTPEnabled, &cred.Status)
+	return
+}
+
+// GetCredentialsByID returns the credentials of the user with the id. Deleted users are not found.
+func (db *DBStorage) GetCredentialsByID(ctx context.Context, id string) (cred models.Credentials, err error) {
+	const query = `
+	SELECT id, username, COALESCE(password, ''), role, COALESCE(totp_secret, ''), totp_enabled, status
+	FROM users WHERE id = $1 AND deleted_at IS NULL;
+	`
+	ctx, done := instrument(ctx, "GetCredentialsByID", query)
+	defer done(&err)
+	err = db.Pool.QueryRow(ctx, query, id).
+		Scan(&cred.UserID, &cred.Username, &cred.PasswordHash, &cred.Role, &cred.TOTPSecret, &cred.TOTPEnabled, &cred.Status)
+	return
+}
+
+// RecordLogin sets the time of the last successful login of the user.
+func (db *DBStorage) RecordLogin(ctx context.Context, username string) (err error) {
+	const query = `
+	UPDATE users SET last_login_at = now() WHERE username = $1;
+	`
+	ctx, done := instrument(ctx, "RecordLogin", query)
+	defer done(&err)
+	_, err = db.Pool.Exec(ctx, query, username)
+	return
+}
+
+func (db *DBStorage) SaveTOTPSecret(ctx context.Context, username, secret string) (err error) {
+	const query = `
+	UPDATE users SET totp_secret = $1, totp_enabled = false, totp_last_step = NULL WHERE username = $2;
+	`
+	ctx, done := instrument(ctx, "SaveTOTPSecret", query)
+	defer done(&err)
+	_, err = db.Pool.Exec(ctx, query, secret, username)
+	return
+}
+
+// EnableTOTP turns on 2FA and replaces the user's recovery codes in a single transaction.
+func (db *DBStorage) EnableTOTP(ctx context.Context, username string, recoveryCodeHashes []string) (err error) {
+	ctx, done := instrument(ctx, "EnableTOTP", "")
+	defer done(&err)
+	tx, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, `UPDATE users SET totp_enabled = true WHERE username = $1;`, username); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, `DELETE FROM recovery_codes WHERE username = $1;`, username); err != nil {
+		return err
+	}
+	for _, hash := range recoveryCodeHashes {
+		_, err = tx.Exec(ctx, `INSERT INTO recovery_codes (username, code_hash) VALUES ($1, $2);`, username, hash)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (db *DBStorage) DisableTOTP(ctx context.Context, username string) (err error) {
+	ctx, done := instrument(ctx, "DisableTOTP", "")
+	defer done(&err)
+	tx, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, `UPDATE users SET totp_secret = NULL, totp_enabled = false, totp_last_step = NULL WHERE username = $1;`, username); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, `DELETE FROM recovery_codes WHERE username = $1;`, username); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// UseTOTPStep records the time step of an accepted TOTP code. It reports false if a code of the same or
+// a later step was already accepted, i.e. the code is replayed.
+func (db *DBStorage) UseTOTPStep(ctx context.Context, username string, step int64) (_ bool, err error) {
+	const query = `
+	UPDATE users SET totp_last_step = $2 WHERE username = $1 AND (totp_last_step IS NULL OR totp_last_step < $2);
+	`
+	ctx, done := instrument(ctx, "UseTOTPStep", query)
+	defer done(&err)
+	tag, err := db.Pool.Exec(ctx, query, username, step)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// UseRecoveryCode marks the recovery code as used. It reports false if the code does not exist or was already used.
+func (db *DBStorage) UseRecoveryCode(ctx context.Context, username, codeHash string) (_ bool, err error) {
+	const query = `
+	UPDATE recovery_codes SET used_at = now() WHERE username = $1 AND code_hash = $2 AND used_at IS NULL;
+	`
+	ctx, done := instrument(ctx, "UseRecoveryCode", query)
+	defer done(&err)
+	tag, err := db.Pool.Exec(ctx, query, username, codeHash)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
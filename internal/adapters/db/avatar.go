@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"user-service/internal/domain/models"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// GetAvatar returns the ID of the user and its avatar, empty if the user has none. It returns false if
+// there is no such user.
+func (db *DBStorage) GetAvatar(ctx context.Context, username string) (userID, avatar string, found bool, err error) {
+	const query = `
+	SELECT id, COALESCE(avatar, '') FROM users WHERE username = $1 AND deleted_at IS NULL;
+	`
+	ctx, done := instrument(ctx, "GetAvatar", query)
+	defer done(&err)
+	err = db.Pool.QueryRow(ctx, query, username).Scan(&userID, &avatar)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", "", false, nil
+	}
+	if err != nil {
+		return "", "", false, err
+	}
+	return userID, avatar, true, nil
+}
+
+// SetAvatar replaces the avatar of the user, records the change in the audit log and returns the previous
+// avatar. It returns false if there is no such user.
+func (db *DBStorage) SetAvatar(ctx context.Context, username, avatar string) (previous string, found bool, err error) {
+	const query = `
+	SELECT id, COALESCE(avatar, '') FROM users WHERE username = $1 AND deleted_at IS NULL FOR UPDATE;
+	`
+	ctx, done := instrument(ctx, "SetAvatar", query)
+	defer done(&err)
+	tx, err := db.begin(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id string
+	err = tx.QueryRow(ctx, query, username).Scan(&id, &previous)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	const update = `
+	UPDATE users SET avatar = $2, updated_at = now() WHERE id = $1;
+	`
+	if _, err = tx.Exec(ctx, update, id, avatar); err != nil {
+		return "", false, err
+	}
+	change := models.AuditChange{Field: "avatar", New: &avatar}
+	if previous != "" {
+		change.Old = &previous
+	}
+	if err = writeAudit(ctx, tx, id, username, models.AuditActionUpdate, []models.AuditChange{change}); err != nil {
+		return "", false, err
+	}
+	return previous, true, tx.Commit(ctx)
+}
+
+// GetMissingUserIDs returns the IDs of the ones given that no user has, e.g. of purged users. Deleted users
+// that have not been purged yet still exist.
+func (db *DBStorage) GetMissingUserIDs(ctx context.Context, ids []string) (_ []string, err error) {
+	const query = `
+	SELECT t.id::text FROM unnest($1::uuid[]) AS t(id)
+	WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = t.id);
+	`
+	ctx, done := instrument(ctx, "GetMissingUserIDs", query)
+	defer done(&err)
+	rows, err := db.Pool.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	missing := []string{}
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		missing = append(missing, id)
+	}
+	return missing, rows.Err()
+}
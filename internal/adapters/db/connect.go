@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+	"user-service/pkg/infra/logger"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// Delays between connection attempts grow exponentially from retryInitialDelay up to retryMaxDelay.
+const (
+	retryInitialDelay = 250 * time.Millisecond
+	retryMaxDelay     = 10 * time.Second
+)
+
+// connect creates the pool and establishes the first connection, retrying with exponential backoff
+// until it succeeds or the context is done. Until the context deadline, connecting is limited by the
+// time left, so a host that does not respond can not hold an attempt past the startup deadline.
+func connect(ctx context.Context, cfg *pgxpool.Config) (*pgxpool.Pool, error) {
+	log := logger.Get().With("host", cfg.ConnConfig.Host, "database", cfg.ConnConfig.Database)
+	if deadline, ok := ctx.Deadline(); ok {
+		cfg.BeforeConnect = limitConnectTimeout(deadline)
+	}
+	for attempt := 1; ; attempt++ {
+		pool, err := pgxpool.ConnectConfig(ctx, cfg)
+		if err == nil {
+			log.Info("connected to database", "attempt", attempt)
+			return pool, nil
+		}
+
+		wait := jitter(retryDelay(attempt), rand.Int63n)
+		log.Warn("database connection failed", "attempt", attempt, "retry_in", wait.String(), "desc", err.Error())
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("connecting to database failed after %d attempts: %w", attempt, err)
+		}
+	}
+}
+
+// limitConnectTimeout returns the BeforeConnect hook lowering the connect timeout of new connections to
+// the time left before the deadline. Connections opened after the deadline keep the configured timeout.
+func limitConnectTimeout(deadline time.Time) func(context.Context, *pgx.ConnConfig) error {
+	return func(_ context.Context, cfg *pgx.ConnConfig) error {
+		if left := time.Until(deadline); left > 0 && (cfg.ConnectTimeout == 0 || cfg.ConnectTimeout > left) {
+			cfg.ConnectTimeout = left
+		}
+		return nil
+	}
+}
+
+// retryDelay returns the delay after the failed attempt, before jitter: retryInitialDelay after the
+// first one, doubled after each next one up to retryMaxDelay.
+func retryDelay(attempt int) time.Duration {
+	delay := retryInitialDelay
+	for i := 1; i < attempt && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, retryMaxDelay)
+}
+
+// jitter returns a random duration between d/2 and d, so that replicas restarted together do not
+// retry in lockstep. int63n returns a random number in [0, n), as rand.Int63n.
+func jitter(d time.Duration, int63n func(n int64) int64) time.Duration {
+	return d/2 + time.Duration(int63n(int64(d-d/2)+1))
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	want := []time.Duration{
+		250 * time.Millisecond,
+		500 * time.Millisecond,
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		10 * time.Second,
+		10 * time.Second,
+	}
+	for i, d := range want {
+		if got := retryDelay(i + 1); got != d {
+			t.Errorf("retryDelay(%d) = %s, want %s", i+1, got, d)
+		}
+	}
+	// no overflow however long the database is unavailable
+	if got := retryDelay(1000); got != retryMaxDelay {
+		t.Errorf("retryDelay(1000) = %s, want %s", got, retryMaxDelay)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	tests := []struct {
+		d        time.Duration
+		min, max time.Duration
+	}{
+		{retryInitialDelay, 125 * time.Millisecond, 250 * time.Millisecond},
+		{retryMaxDelay, 5 * time.Second, 10 * time.Second},
+		{3, 1, 3},
+		{1, 0, 1},
+	}
+	lowest := func(int64) int64 { return 0 }
+	highest := func(n int64) int64 { return n - 1 }
+	for _, tt := range tests {
+		if got := jitter(tt.d, lowest); got != tt.min {
+			t.Errorf("jitter(%s) with the lowest random number = %s, want %s", tt.d, got, tt.min)
+		}
+		if got := jitter(tt.d, highest); got != tt.max {
+			t.Errorf("jitter(%s) with the highest random number = %s, want %s", tt.d, got, tt.max)
+		}
+		rnd := rand.New(rand.NewSource(1))
+		for i := 0; i < 1000; i++ {
+			if got := jitter(tt.d, rnd.Int63n); got < tt.min || got > tt.max {
+				t.Fatalf("jitter(%s) = %s, want between %s and %s", tt.d, got, tt.min, tt.max)
+			}
+		}
+	}
+}
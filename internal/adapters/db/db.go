@@ -3,64 +3,365 @@ package db
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 	"user-service/internal/domain/models"
 	"user-service/internal/ports"
+	"user-service/pkg/usernames"
 
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	_ "github.com/lib/pq"
 )
 
+// uniqueViolation is the Postgres error code of a unique constraint violation.
+const uniqueViolation = "23505"
+
+// usernameNormConstraint is the unique index on the skeletons of usernames.
+const usernameNormConstraint = "users_username_norm_key"
+
 type DBStorage struct {
-	Pool *pgxpool.Pool
+	Pool    *pgxpool.Pool
+	appName string // application_name of the connections, tagged with the request ID in transactions
 }
 
 var _ ports.UserStorage = (*DBStorage)(nil)
-var ctx = context.Background()
+var _ ports.AuthStorage = (*DBStorage)(nil)
+var _ ports.APIKeyStorage = (*DBStorage)(nil)
+var _ ports.OIDCStorage = (*DBStorage)(nil)
+var _ ports.SessionStorage = (*DBStorage)(nil)
+var _ ports.HealthStorage = (*DBStorage)(nil)
+var _ ports.PreferencesStorage = (*DBStorage)(nil)
+var _ ports.AvatarStorage = (*DBStorage)(nil)
+
+type DBOptions struct {
+	URL               string
+	MaxConns          int32         // 0 keeps the pgxpool default
+	MinConns          int32         // connections kept open even when idle
+	HealthCheckPeriod time.Duration // how often idle connections are checked, 0 keeps the pgxpool default
+	MaxConnLifetime   time.Duration // connections are closed this long after they were opened, 0 keeps the pgxpool default
+	MaxConnIdleTime   time.Duration // idle connections are closed after this long, 0 keeps the pgxpool default
+	StartupTimeout    time.Duration // deadline for connecting and applying migrations
+}
+
+// New connects to the database, retrying while it is unavailable, applies pending migrations and returns
+// a new instance of DBStorage. It fails if the startup does not complete within opts.StartupTimeout.
+func New(ctx context.Context, opts DBOptions) (*DBStorage, error) {
+	cfg, err := pgxpool.ParseConfig(opts.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database url: %w", err)
+	}
+	if opts.MaxConns > 0 {
+		cfg.MaxConns = opts.MaxConns
+	}
+	cfg.MinConns = opts.MinConns
+	if opts.HealthCheckPeriod > 0 {
+		cfg.HealthCheckPeriod = opts.HealthCheckPeriod
+	}
+	if opts.MaxConnLifetime > 0 {
+		cfg.MaxConnLifetime = opts.MaxConnLifetime
+	}
+	if opts.MaxConnIdleTime > 0 {
+		cfg.MaxConnIdleTime = opts.MaxConnIdleTime
+	}
+	appName := cfg.ConnConfig.RuntimeParams["application_name"]
+	if appName == "" {
+		appName = defaultApplicationName
+		cfg.ConnConfig.RuntimeParams["application_name"] = appName
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.StartupTimeout)
+	defer cancel()
 
-// New establishes one connection and returns a new instance of DBStorage.
-func New(ctx context.Context, conn string) (*DBStorage, error) {
-	time.Sleep(time.Second)
-	pool, err := pgxpool.Connect(ctx, conn)
+	pool, err := connect(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
-	return &DBStorage{
-		Pool: pool,
-	}, nil
+	db := &DBStorage{
+		Pool:    pool,
+		appName: appName,
+	}
+	if err = db.migrate(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	if err = db.normalizeUsernames(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("normalizing usernames failed: %w", err)
+	}
+	return db, nil
 }
 
-func (db *DBStorage) SaveUser(user models.User) (err error) {
+// Close closes all connections of the pool.
+func (db *DBStorage) Close() {
+	db.Pool.Close()
+}
+
+// SaveUser creates the user and records it in the audit log in the same transaction.
+func (db *DBStorage) SaveUser(ctx context.Context, user models.User) (err error) {
 	const query = `
-	INSERT INTO users (username, password, first_name, last_name, email, phone) VALUES ($1, $2, $3, $4, $5, $6);
+	INSERT INTO users (id, username, username_norm, password, first_name, last_name, email, phone, status, attributes)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
 	`
-	_, err = db.Pool.Exec(ctx, query, user.Username, user.Password, user.FirstName, user.LastName, user.Email, user.Phone)
-	return err
+	ctx, done := instrument(ctx, "SaveUser", query)
+	defer done(&err)
+	tx, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	attrs, err := attributesJSON(user.Attributes)
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, query, user.ID, user.Username, usernames.Skeleton(user.Username), user.Password, user.FirstName, user.LastName, user.Email, user.Phone, user.Status, attrs); err != nil {
+		// the username of a deleted user is reserved until it is purged
+		if pgErr := (*pgconn.PgError)(nil); errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return models.ErrUserAlreadyExists
+		}
+		return err
+	}
+	if err = writeAudit(ctx, tx, user.ID, user.Username, models.AuditActionCreate, diffUsers(nil, &user)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-func (db *DBStorage) GetUser(username string) (user models.GetUserResponse, err error) {
+// userColumns are the columns scanned by scanUser.
+const userColumns = `id, username, first_name, last_name, email, phone, status, attributes, avatar, created_at, updated_at, last_login_at`
+
+func scanUser(row pgx.Row) (user models.GetUserResponse, err error) {
+	var avatar *string
+	err = row.Scan(&user.ID, &user.Username, &user.FirstName, &user.LastName, &user.Email, &user.Phone, &user.Status,
+		&user.Attributes, &avatar, &user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt)
+	if err == nil && avatar != nil {
+		user.AvatarURL = models.AvatarURL(user.ID, *avatar)
+	}
+	return
+}
+
+func (db *DBStorage) GetUser(ctx context.Context, username string) (_ models.GetUserResponse, err error) {
 	const query = `
-	SELECT username, first_name, last_name, email, phone FROM users WHERE username = $1;
+	SELECT ` + userColumns + ` FROM users WHERE username = $1 AND deleted_at IS NULL;
 	`
-	err = db.Pool.QueryRow(ctx, query, username).
-		Scan(&user.Username, &user.FirstName, &user.LastName, &user.Email, &user.Phone)
-	return
+	ctx, done := instrument(ctx, "GetUser", query)
+	defer done(&err)
+	return scanUser(db.Pool.QueryRow(ctx, query, username))
 }
 
-func (db *DBStorage) UpdateUser(username string, user models.User) (err error) {
+// GetUsernameByID returns the current username of the user with the id, including deleted users.
+func (db *DBStorage) GetUsernameByID(ctx context.Context, id string) (username string, err error) {
+	const query = `
+	SELECT username FROM users WHERE id = $1;
+	`
+	ctx, done := instrument(ctx, "GetUsernameByID", query)
+	defer done(&err)
+	err = db.Pool.QueryRow(ctx, query, id).Scan(&username)
+	return username, err
+}
+
+// attributeValues returns the JSON values an attribute filter value matches: the string itself and, if the
+// value is a JSON number, boolean or null, that value.
+func attributeValues(s string) []any {
+	values := []any{s}
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err == nil {
+		switch v.(type) {
+		case nil, bool, float64:
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// ListUsers returns the users matching the filter, oldest first.
+func (db *DBStorage) ListUsers(ctx context.Context, filter models.UserFilter) (_ []models.GetUserResponse, err error) {
+	conds := []string{"deleted_at IS NULL"}
+	var args []any
+	where := func(column, op string, t *time.Time) {
+		if t != nil {
+			args = append(args, *t)
+			conds = append(conds, fmt.Sprintf("%s %s $%d", column, op, len(args)))
+		}
+	}
+	where("created_at", ">=", filter.CreatedFrom)
+	where("created_at", "<", filter.CreatedTo)
+	where("updated_at", ">=", filter.UpdatedFrom)
+	where("updated_at", "<", filter.UpdatedTo)
+	where("last_login_at", ">=", filter.LastLoginFrom)
+	where("last_login_at", "<", filter.LastLoginTo)
+	// sorted, so that the same filters produce the same query
+	names := make([]string, 0, len(filter.Attributes))
+	for name := range filter.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// the GIN index serves containment of {"name": value}
+		var alts []string
+		for _, v := range attributeValues(filter.Attributes[name]) {
+			b, err := json.Marshal(map[string]any{name: v})
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, string(b))
+			alts = append(alts, fmt.Sprintf("attributes @> $%d", len(args)))
+		}
+		conds = append(conds, "("+strings.Join(alts, " OR ")+")")
+	}
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`
+	SELECT %s FROM users WHERE %s
+	ORDER BY created_at, username LIMIT $%d OFFSET $%d;
+	`, userColumns, strings.Join(conds, " AND "), len(args)-1, len(args))
+
+	ctx, done := instrument(ctx, "ListUsers", query)
+	defer done(&err)
+	rows, err := db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.GetUserResponse{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// UpdateUser updates the user and records the changed fields in the audit log in the same transaction.
+// A changed username is kept in the username history. The skeleton of the username is written only when
+// the username changes: users created before usernames were normalized may have none, because theirs
+// conflicts with another user's, and can still be updated until they are renamed. Renaming to a username
+// whose skeleton is taken fails with ErrUsernameRenameRequired. An empty password keeps the stored one.
+func (db *DBStorage) UpdateUser(ctx context.Context, username string, user models.User) (err error) {
 	const query = `
 	UPDATE users
-	SET username = $1, password = $2, first_name = $3, last_name = $4, email = $5, phone = $6
-	WHERE username = $7;
+	SET username = $1, username_norm = CASE WHEN username = $1 THEN username_norm ELSE $2 END, password = $3, first_name = $4, last_name = $5, email = $6, phone = $7, attributes = $8,
+		updated_at = now()
+	WHERE username = $9;
 	`
-	_, err = db.Pool.Exec(ctx, query, user.Username, user.Password, user.FirstName, user.LastName, user.Email, user.Phone, username)
-	return
+	ctx, done := instrument(ctx, "UpdateUser", query)
+	defer done(&err)
+	tx, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := lockUser(ctx, tx, username)
+	if err != nil {
+		return err
+	}
+	if user.Password == "" {
+		user.Password = before.Password
+	}
+	attrs, err := attributesJSON(user.Attributes)
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, query, user.Username, usernames.Skeleton(user.Username), user.Password, user.FirstName, user.LastName, user.Email, user.Phone, attrs, username); err != nil {
+		if pgErr := (*pgconn.PgError)(nil); errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			if pgErr.ConstraintName == usernameNormConstraint {
+				return models.ErrUsernameRenameRequired
+			}
+			return models.ErrUserAlreadyExists
+		}
+		return err
+	}
+	if user.Username != username {
+		const history = `INSERT INTO username_history (user_id, username, username_norm) VALUES ($1, $2, $3);`
+		if _, err = tx.Exec(ctx, history, before.ID, username, usernames.Skeleton(username)); err != nil {
+			return err
+		}
+	}
+	if err = writeAudit(ctx, tx, before.ID, user.Username, models.AuditActionUpdate, diffUsers(&before, &user)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-func (db *DBStorage) DeleteUser(username string) (err error) {
-	const query = `	
-	DELETE FROM users WHERE username = $1;
+// DeleteUser marks the user as deleted, ends the user's sessions and records the deleted values in the
+// audit log in the same transaction. The user is removed by PurgeDeletedUsers after the retention period.
+func (db *DBStorage) DeleteUser(ctx context.Context, username string) (err error) {
+	const query = `
+	UPDATE users SET deleted_at = now() WHERE username = $1;
 	`
-	_, err = db.Pool.Exec(ctx, query, username)
-	return
+	ctx, done := instrument(ctx, "DeleteUser", query)
+	defer done(&err)
+	tx, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := lockUser(ctx, tx, username)
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, query, username); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, `DELETE FROM sessions WHERE username = $1;`, username); err != nil {
+		return err
+	}
+	if err = writeAudit(ctx, tx, before.ID, username, models.AuditActionDelete, diffUsers(&before, nil)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// RestoreUser clears the deletion mark of the user and records it in the audit log in the same transaction.
+// It reports false if there is no deleted user with the username.
+func (db *DBStorage) RestoreUser(ctx context.Context, username string) (_ bool, err error) {
+	const query = `
+	UPDATE users SET deleted_at = NULL WHERE username = $1 AND deleted_at IS NOT NULL RETURNING id;
+	`
+	ctx, done := instrument(ctx, "RestoreUser", query)
+	defer done(&err)
+	tx, err := db.begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id string
+	if err = tx.QueryRow(ctx, query, username).Scan(&id); errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if err = writeAudit(ctx, tx, id, username, models.AuditActionRestore, []models.AuditChange{}); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+// PurgeDeletedUsers permanently removes the users deleted before the time and records the removal in the
+// audit log in the same statement. It returns the number of removed users.
+func (db *DBStorage) PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) (_ int64, err error) {
+	const query = `
+	WITH purged AS (
+		DELETE FROM users WHERE deleted_at < $1 RETURNING id, username
+	)
+	INSERT INTO user_audit_log (user_id, username, action, actor) SELECT id, username, $2, 'system' FROM purged;
+	`
+	ctx, done := instrument(ctx, "PurgeDeletedUsers", query)
+	defer done(&err)
+	tag, err := db.Pool.Exec(ctx, query, deletedBefore, models.AuditActionPurge)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
 }
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+)
+
+// Ping checks that a connection to the database can be acquired and used.
+func (db *DBStorage) Ping(ctx context.Context) error {
+	return db.Pool.Ping(ctx)
+}
+
+// PendingMigrations returns the embedded migrations that are not recorded in schema_migrations.
+func (db *DBStorage) PendingMigrations(ctx context.Context) ([]string, error) {
+	rows, err := db.Pool.Query(ctx, `SELECT version FROM schema_migrations;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err = rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	names, err := migrationVersions()
+	if err != nil {
+		return nil, err
+	}
+	var pending []string
+	for _, name := range names {
+		if !applied[name] {
+			pending = append(pending, name)
+		}
+	}
+	return pending, nil
+}
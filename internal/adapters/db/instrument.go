@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"user-service/pkg/infra/metrics"
+
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("user-service/internal/adapters/db")
+
+// instrument starts a span for the storage operation and returns the function that ends it and records
+// the operation latency. The statement is attached to the span, if the operation runs a single query.
+// The returned function is deferred with a pointer to the named error result of the operation.
+func instrument(ctx context.Context, operation, statement string) (context.Context, func(err *error)) {
+	start := time.Now()
+	attrs := []attribute.KeyValue{semconv.DBSystemPostgreSQL, semconv.DBOperation(operation)}
+	if statement != "" {
+		attrs = append(attrs, semconv.DBStatement(strings.Join(strings.Fields(statement), " ")))
+	}
+	ctx, span := tracer.Start(ctx, "db."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+
+	return ctx, func(err *error) {
+		result := "ok"
+		switch {
+		case errors.Is(*err, pgx.ErrNoRows):
+			result = "not_found"
+		case *err != nil:
+			result = "error"
+			span.RecordError(*err)
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+		span.End()
+		metrics.StorageOperationDuration.WithLabelValues(operation, result).Observe(time.Since(start).Seconds())
+	}
+}
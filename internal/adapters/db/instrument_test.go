@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"user-service/pkg/infra/metrics"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// storageOperations returns the number of recorded operations by result, scraped from the metrics endpoint,
+// and the scraped page.
+func storageOperations(t *testing.T, operation string) (map[string]float64, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	counts := map[string]float64{}
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		for _, result := range []string{"ok", "not_found", "error"} {
+			prefix := `user_service_storage_operation_duration_seconds_count{operation="` + operation + `",result="` + result + `"} `
+			if strings.HasPrefix(line, prefix) {
+				counts[result], _ = strconv.ParseFloat(strings.TrimPrefix(line, prefix), 64)
+			}
+		}
+	}
+	return counts, rec.Body.String()
+}
+
+func TestInstrumentRecordsOperationResult(t *testing.T) {
+	const operation = "TestOperation"
+	before, _ := storageOperations(t, operation)
+	for _, result := range []error{nil, nil, pgx.ErrNoRows, errors.New("connection refused")} {
+		_, done := instrument(context.Background(), operation, "SELECT 1")
+		err := result
+		done(&err)
+	}
+
+	after, scraped := storageOperations(t, operation)
+	if !strings.Contains(scraped, "# TYPE user_service_storage_operation_duration_seconds histogram\n") {
+		t.Error("metrics do not contain the storage_operation_duration_seconds histogram")
+	}
+	for result, want := range map[string]float64{"ok": 2, "not_found": 1, "error": 1} {
+		if got := after[result] - before[result]; got != want {
+			t.Errorf("%s operations increased by %v, want %v", result, got, want)
+		}
+	}
+}
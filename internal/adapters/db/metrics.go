@@ -0,0 +1,58 @@
+package db
+
+import (
+	"user-service/pkg/infra/metrics"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolCollector exports the connection pool statistics as Prometheus metrics.
+type PoolCollector struct {
+	pool *pgxpool.Pool
+
+	connections     *prometheus.Desc
+	acquires        *prometheus.Desc
+	emptyAcquires   *prometheus.Desc
+	canceled        *prometheus.Desc
+	acquireDuration *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*PoolCollector)(nil)
+
+// NewPoolCollector returns a collector of the storage pool statistics.
+func (db *DBStorage) NewPoolCollector() *PoolCollector {
+	return &PoolCollector{
+		pool:            db.Pool,
+		connections:     metrics.NewDesc("db_pool_connections", "Number of pool connections by state.", "state"),
+		acquires:        metrics.NewDesc("db_pool_acquires_total", "Number of successful connection acquires."),
+		emptyAcquires:   metrics.NewDesc("db_pool_empty_acquires_total", "Number of acquires that had to wait for a connection."),
+		canceled:        metrics.NewDesc("db_pool_canceled_acquires_total", "Number of acquires canceled by the context."),
+		acquireDuration: metrics.NewDesc("db_pool_acquire_duration_seconds_total", "Total time spent acquiring connections."),
+	}
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.connections
+	ch <- c.acquires
+	ch <- c.emptyAcquires
+	ch <- c.canceled
+	ch <- c.acquireDuration
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	for state, n := range map[string]int32{
+		"acquired":     stat.AcquiredConns(),
+		"idle":         stat.IdleConns(),
+		"constructing": stat.ConstructingConns(),
+		"total":        stat.TotalConns(),
+		"max":          stat.MaxConns(),
+	} {
+		ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(n), state)
+	}
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceled, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+}
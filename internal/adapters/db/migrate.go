@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"user-service/pkg/infra/logger"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// migrate applies the embedded migrations that have not been applied yet, in lexical order of file names.
+// Each migration runs in its own transaction together with its record in schema_migrations.
+func (db *DBStorage) migrate(ctx context.Context) error {
+	const createTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version VARCHAR(255) PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+	`
+	if _, err := db.Pool.Exec(ctx, createTable); err != nil {
+		return fmt.Errorf("creating schema_migrations failed: %w", err)
+	}
+
+	names, err := migrationVersions()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err = db.applyMigration(ctx, name); err != nil {
+			return fmt.Errorf("migration %s failed: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// applyMigration runs a single migration unless it is already recorded. The advisory lock serializes
+// concurrent replicas starting at the same time.
+func (db *DBStorage) applyMigration(ctx context.Context, name string) error {
+	body, err := migrations.ReadFile("migrations/" + name)
+	if err != nil {
+		return err
+	}
+	tx, err := db.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('schema_migrations'));`); err != nil {
+		return err
+	}
+	var applied bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1);`, name).Scan(&applied)
+	if err != nil || applied {
+		return err
+	}
+	if _, err = tx.Exec(ctx, string(body)); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, `INSERT INTO schema_migrations (version) VALUES ($1);`, name); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+	logger.Get().Info("migration applied", "version", name)
+	return nil
+}
+
+// migrationVersions returns the names of the embedded migration files in the order they must be applied.
+func migrationVersions() ([]string, error) {
+	entries, err := fs.ReadDir(migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".sql") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id VARCHAR(32) PRIMARY KEY,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    username VARCHAR(255) NOT NULL REFERENCES users (username) ON UPDATE CASCADE ON DELETE CASCADE,
+    amr TEXT[] NOT NULL DEFAULT '{}',
+    user_agent TEXT,
+    ip VARCHAR(64),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS sessions_username_idx ON sessions (username);
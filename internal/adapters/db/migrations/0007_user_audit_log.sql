@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS user_audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    actor VARCHAR(255) NOT NULL DEFAULT '',
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    changes JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS user_audit_log_username_idx ON user_audit_log (username, id DESC);
+
+-- the audit log is append-only
+CREATE OR REPLACE FUNCTION user_audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'user_audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS user_audit_log_append_only ON user_audit_log;
+CREATE TRIGGER user_audit_log_append_only BEFORE UPDATE OR DELETE OR TRUNCATE ON user_audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION user_audit_log_append_only();
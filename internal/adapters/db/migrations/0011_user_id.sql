@@ -0,0 +1,36 @@
+-- UUIDv7: 48-bit Unix time in milliseconds followed by random bits, so that ids sort by creation time.
+-- The service generates ids itself, the function fills in the existing users.
+CREATE OR REPLACE FUNCTION uuid_generate_v7(ts TIMESTAMPTZ) RETURNS UUID AS $$
+    SELECT encode(
+        set_bit(set_bit(set_bit(set_bit(
+            overlay(uuid_send(gen_random_uuid())
+                placing substring(int8send(floor(extract(epoch FROM ts) * 1000)::BIGINT) FROM 3)
+                FROM 1 FOR 6),
+        52, 1), 53, 1), 54, 1), 55, 0),
+    'hex')::UUID;
+$$ LANGUAGE SQL VOLATILE;
+
+ALTER TABLE users ADD COLUMN id UUID;
+UPDATE users SET id = uuid_generate_v7(created_at);
+ALTER TABLE users ALTER COLUMN id SET NOT NULL;
+
+-- username stays unique and referenced by the other tables, but is no longer the primary key
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (username);
+ALTER TABLE recovery_codes DROP CONSTRAINT recovery_codes_username_fkey;
+ALTER TABLE oauth_codes DROP CONSTRAINT oauth_codes_username_fkey;
+ALTER TABLE sessions DROP CONSTRAINT sessions_username_fkey;
+ALTER TABLE users DROP CONSTRAINT users_pkey;
+ALTER TABLE users ADD PRIMARY KEY (id);
+ALTER TABLE recovery_codes ADD CONSTRAINT recovery_codes_username_fkey
+    FOREIGN KEY (username) REFERENCES users (username) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE oauth_codes ADD CONSTRAINT oauth_codes_username_fkey
+    FOREIGN KEY (username) REFERENCES users (username) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE sessions ADD CONSTRAINT sessions_username_fkey
+    FOREIGN KEY (username) REFERENCES users (username) ON UPDATE CASCADE ON DELETE CASCADE;
+
+-- audit entries follow the user across renames
+ALTER TABLE user_audit_log ADD COLUMN user_id UUID;
+ALTER TABLE user_audit_log DISABLE TRIGGER user_audit_log_append_only;
+UPDATE user_audit_log l SET user_id = u.id FROM users u WHERE u.username = l.username;
+ALTER TABLE user_audit_log ENABLE TRIGGER user_audit_log_append_only;
+CREATE INDEX user_audit_log_user_id_idx ON user_audit_log (user_id, id DESC);
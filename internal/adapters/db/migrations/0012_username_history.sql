@@ -0,0 +1,10 @@
+-- usernames released by renames: old names redirect to the user for a while and cannot be taken by others
+CREATE TABLE IF NOT EXISTS username_history (
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    username    TEXT NOT NULL,
+    released_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS username_history_username_idx ON username_history (username, released_at DESC);
+CREATE INDEX IF NOT EXISTS username_history_released_at_idx ON username_history (released_at);
@@ -0,0 +1,8 @@
+-- the skeleton of the username (see pkg/usernames) makes usernames unique regardless of case, Unicode form
+-- and lookalike characters; existing rows are filled in by the service after the migrations
+ALTER TABLE users ADD COLUMN IF NOT EXISTS username_norm TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_norm_key ON users (username_norm);
+
+ALTER TABLE username_history ADD COLUMN IF NOT EXISTS username_norm TEXT;
+DROP INDEX IF EXISTS username_history_username_idx;
+CREATE INDEX IF NOT EXISTS username_history_username_norm_idx ON username_history (username_norm, released_at DESC);
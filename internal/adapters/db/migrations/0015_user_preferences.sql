@@ -0,0 +1,6 @@
+-- preferences the users have changed from the defaults defined in the service
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id     UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    preferences JSONB NOT NULL DEFAULT '{}',
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
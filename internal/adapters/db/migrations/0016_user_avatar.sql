@@ -0,0 +1,2 @@
+-- the current avatar as "<version>.<extension>"; the images are kept in the blob storage
+ALTER TABLE users ADD COLUMN avatar TEXT;
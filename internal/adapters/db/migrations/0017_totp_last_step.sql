@@ -0,0 +1,2 @@
+-- the time step of the last accepted TOTP code, so that codes cannot be replayed within their validity window
+ALTER TABLE users ADD COLUMN totp_last_step BIGINT;
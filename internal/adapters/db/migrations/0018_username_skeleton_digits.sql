@@ -0,0 +1,5 @@
+-- skeletons no longer fold the digits 0 and 1 into the letters o and l (see pkg/usernames), so that names
+-- such as user10 and userlo are different users; the skeletons are filled in again by the service after
+-- the migrations
+UPDATE users SET username_norm = NULL;
+UPDATE username_history SET username_norm = NULL;
@@ -0,0 +1,4 @@
+-- the time a bucket takes to refill from empty, so that idle buckets are removed only once they are full
+-- whatever the rule; buckets written before are kept for a day, as their rules are unknown
+ALTER TABLE rate_limits ADD COLUMN refill_seconds DOUBLE PRECISION NOT NULL DEFAULT 86400;
+ALTER TABLE rate_limits ALTER COLUMN refill_seconds DROP DEFAULT;
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"user-service/internal/domain/models"
+
+	"github.com/jackc/pgx/v4"
+)
+
+func (db *DBStorage) SaveOAuthClient(ctx context.Context, client models.OAuthClient) (err error) {
+	const query = `
+	INSERT INTO oauth_clients (client_id, secret_hash, name, redirect_uris, public, created_at) VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6);
+	`
+	ctx, done := instrument(ctx, "SaveOAuthClient", query)
+	defer done(&err)
+	_, err = db.Pool.Exec(ctx, query, client.ClientID, client.SecretHash, client.Name, client.RedirectURIs, client.Public, client.CreatedAt)
+	return
+}
+
+func (db *DBStorage) GetOAuthClient(ctx context.Context, clientID string) (client models.OAuthClient, err error) {
+	const query = `
+	SELECT client_id, COALESCE(secret_hash, ''), name, redirect_uris, public, created_at FROM oauth_clients WHERE client_id = $1;
+	`
+	ctx, done := instrument(ctx, "GetOAuthClient", query)
+	defer done(&err)
+	err = db.Pool.QueryRow(ctx, query, clientID).
+		Scan(&client.ClientID, &client.SecretHash, &client.Name, &client.RedirectURIs, &client.Public, &client.CreatedAt)
+	return
+}
+
+func (db *DBStorage) ListOAuthClients(ctx context.Context) (_ []models.OAuthClient, err error) {
+	const query = `
+	SELECT client_id, name, redirect_uris, public, created_at FROM oauth_clients ORDER BY created_at;
+	`
+	ctx, done := instrument(ctx, "ListOAuthClients", query)
+	defer done(&err)
+	rows, err := db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := []models.OAuthClient{}
+	for rows.Next() {
+		var client models.OAuthClient
+		if err = rows.Scan(&client.ClientID, &client.Name, &client.RedirectURIs, &client.Public, &client.CreatedAt); err != nil {
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+	return clients, rows.Err()
+}
+
+// DeleteOAuthClient removes the client together with its pending codes. It reports false if there is no such client.
+func (db *DBStorage) DeleteOAuthClient(ctx context.Context, clientID string) (_ bool, err error) {
+	const query = `
+	DELETE FROM oauth_clients WHERE client_id = $1;
+	`
+	ctx, done := instrument(ctx, "DeleteOAuthClient", query)
+	defer done(&err)
+	tag, err := db.Pool.Exec(ctx, query, clientID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (db *DBStorage) SaveAuthorizationCode(ctx context.Context, code models.AuthorizationCode) (err error) {
+	const query = `
+	INSERT INTO oauth_codes (code_hash, client_id, username, redirect_uri, scope, nonce, code_challenge, auth_time, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+	`
+	ctx, done := instrument(ctx, "SaveAuthorizationCode", query)
+	defer done(&err)
+	_, err = db.Pool.Exec(ctx, query, code.CodeHash, code.ClientID, code.Username, code.RedirectURI, code.Scope,
+		code.Nonce, code.CodeChallenge, code.AuthTime, code.ExpiresAt)
+	return
+}
+
+// ConsumeAuthorizationCode deletes the code and returns it, so that each code can be exchanged only once.
+// Expired codes of all clients are removed along the way.
+func (db *DBStorage) ConsumeAuthorizationCode(ctx context.Context, codeHash string) (code models.AuthorizationCode, err error) {
+	const query = `
+	DELETE FROM oauth_codes WHERE code_hash = $1 OR expires_at < now()
+	RETURNING code_hash, client_id, username, redirect_uri, scope, COALESCE(nonce, ''), COALESCE(code_challenge, ''), auth_time, expires_at;
+	`
+	ctx, done := instrument(ctx, "ConsumeAuthorizationCode", query)
+	defer done(&err)
+	rows, err := db.Pool.Query(ctx, query, codeHash)
+	if err != nil {
+		return code, err
+	}
+	defer rows.Close()
+
+	found := false
+	for rows.Next() {
+		var c models.AuthorizationCode
+		err = rows.Scan(&c.CodeHash, &c.ClientID, &c.Username, &c.RedirectURI, &c.Scope, &c.Nonce, &c.CodeChallenge, &c.AuthTime, &c.ExpiresAt)
+		if err != nil {
+			return code, err
+		}
+		if c.CodeHash == codeHash {
+			code, found = c, true
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return code, err
+	}
+	if !found {
+		return code, pgx.ErrNoRows
+	}
+	return code, nil
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"user-service/internal/domain/models"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// GetPreferences returns the preferences the user has set. It returns false if there is no such user.
+func (db *DBStorage) GetPreferences(ctx context.Context, username string) (_ models.PreferencesPatch, _ bool, err error) {
+	const query = `
+	SELECT p.preferences
+	FROM users u LEFT JOIN user_preferences p ON p.user_id = u.id
+	WHERE u.username = $1 AND u.deleted_at IS NULL;
+	`
+	ctx, done := instrument(ctx, "GetPreferences", query)
+	defer done(&err)
+	var raw []byte
+	err = db.Pool.QueryRow(ctx, query, username).Scan(&raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.PreferencesPatch{}, false, nil
+	}
+	if err != nil {
+		return models.PreferencesPatch{}, false, err
+	}
+	prefs, err := decodePreferences(raw)
+	return prefs, err == nil, err
+}
+
+// SavePreferences replaces the preferences the user has set. It returns false if there is no such user.
+func (db *DBStorage) SavePreferences(ctx context.Context, username string, prefs models.PreferencesPatch) (_ bool, err error) {
+	const query = `
+	INSERT INTO user_preferences (user_id, preferences)
+	SELECT id, $2 FROM users WHERE username = $1 AND deleted_at IS NULL
+	ON CONFLICT (user_id) DO UPDATE SET preferences = EXCLUDED.preferences, updated_at = now();
+	`
+	ctx, done := instrument(ctx, "SavePreferences", query)
+	defer done(&err)
+	body, err := json.Marshal(prefs)
+	if err != nil {
+		return false, err
+	}
+	tag, err := db.Pool.Exec(ctx, query, username, string(body))
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// MergePreferences sets the preferences in the patch, keeping the others the user has set, and returns
+// the result. The user's row is locked, so that concurrent patches are not lost. It returns false if
+// there is no such user.
+func (db *DBStorage) MergePreferences(ctx context.Context, username string, patch models.PreferencesPatch) (_ models.PreferencesPatch, _ bool, err error) {
+	const query = `
+	SELECT u.id, p.preferences
+	FROM users u LEFT JOIN user_preferences p ON p.user_id = u.id
+	WHERE u.username = $1 AND u.deleted_at IS NULL
+	FOR UPDATE OF u;
+	`
+	ctx, done := instrument(ctx, "MergePreferences", query)
+	defer done(&err)
+	tx, err := db.begin(ctx)
+	if err != nil {
+		return models.PreferencesPatch{}, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		id  string
+		raw []byte
+	)
+	err = tx.QueryRow(ctx, query, username).Scan(&id, &raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.PreferencesPatch{}, false, nil
+	}
+	if err != nil {
+		return models.PreferencesPatch{}, false, err
+	}
+	current, err := decodePreferences(raw)
+	if err != nil {
+		return models.PreferencesPatch{}, false, err
+	}
+	merged := current.Merge(patch)
+	body, err := json.Marshal(merged)
+	if err != nil {
+		return models.PreferencesPatch{}, false, err
+	}
+	const upsert = `
+	INSERT INTO user_preferences (user_id, preferences) VALUES ($1, $2)
+	ON CONFLICT (user_id) DO UPDATE SET preferences = EXCLUDED.preferences, updated_at = now();
+	`
+	if _, err = tx.Exec(ctx, upsert, id, string(body)); err != nil {
+		return models.PreferencesPatch{}, false, err
+	}
+	return merged, true, tx.Commit(ctx)
+}
+
+// decodePreferences decodes the stored preferences; NULL, for users without stored preferences, means none set.
+func decodePreferences(raw []byte) (prefs models.PreferencesPatch, err error) {
+	if raw != nil {
+		err = json.Unmarshal(raw, &prefs)
+	}
+	return prefs, err
+}
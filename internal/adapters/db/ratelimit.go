@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"time"
+	"user-service/pkg/infra/logger"
+	"user-service/pkg/ratelimit"
+)
+
+// rateLimitCleanupInterval is how often idle buckets are removed.
+const rateLimitCleanupInterval = time.Hour
+
+// RateLimitStore keeps token buckets in Postgres, so that all replicas share the limits.
+type RateLimitStore struct {
+	db          *DBStorage
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+var _ ratelimit.Store = (*RateLimitStore)(nil)
+
+// NewRateLimitStore returns a rate limit store backed by the database.
+func NewRateLimitStore(db *DBStorage) *RateLimitStore {
+	return &RateLimitStore{db: db}
+}
+
+// Take refills and takes a token from the bucket in a single statement, so concurrent requests
+// from several replicas can not overdraw it.
+func (s *RateLimitStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (tokens float64, allowed bool, err error) {
+	const query = `
+	INSERT INTO rate_limits AS b (key, tokens, allowed, updated_at, refill_seconds)
+	VALUES ($1, $2::float8 - 1, true, now(), $2::float8 / $3::float8)
+	ON CONFLICT (key) DO UPDATE SET
+		tokens = CASE
+			WHEN LEAST($2, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at) * $3) >= 1
+			THEN LEAST($2, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at) * $3) - 1
+			ELSE LEAST($2, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at) * $3)
+		END,
+		allowed = LEAST($2, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at) * $3) >= 1,
+		updated_at = now(),
+		refill_seconds = $2::float8 / $3::float8
+	RETURNING tokens, allowed;
+	`
+	ctx, done := instrument(ctx, "TakeRateLimit", query)
+	defer done(&err)
+	s.cleanup(ctx)
+	err = s.db.Pool.QueryRow(ctx, query, key, float64(limit.Burst), limit.Rate).Scan(&tokens, &allowed)
+	return
+}
+
+// cleanup removes the buckets idle for longer than their rule takes to refill them from empty: they are
+// full and indistinguishable from new ones. It runs at most once per cleanup interval.
+func (s *RateLimitStore) cleanup(ctx context.Context) {
+	s.mu.Lock()
+	if time.Since(s.lastCleanup) < rateLimitCleanupInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastCleanup = time.Now()
+	s.mu.Unlock()
+
+	const query = `
+	DELETE FROM rate_limits WHERE updated_at < now() - make_interval(secs => refill_seconds);
+	`
+	if _, err := s.db.Pool.Exec(ctx, query); err != nil {
+		logger.FromContext(ctx).Warn("rate limit cleanup failed", "desc", err.Error())
+	}
+}
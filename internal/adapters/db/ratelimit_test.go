@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+	"user-service/pkg/ratelimit"
+
+	"github.com/google/uuid"
+)
+
+// ageBucket moves the last update of the bucket back, as if it was idle for d.
+func ageBucket(t *testing.T, storage *DBStorage, key string, d time.Duration) {
+	t.Helper()
+	const query = `UPDATE rate_limits SET updated_at = updated_at - make_interval(secs => $2) WHERE key = $1;`
+	if _, err := storage.Pool.Exec(context.Background(), query, key, d.Seconds()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRateLimitTake(t *testing.T) {
+	storage := testStorage(t)
+	store := NewRateLimitStore(storage)
+	store.lastCleanup = time.Now()
+	ctx := context.Background()
+	key := "test|" + uuid.NewString()
+	limit := ratelimit.Limit{Rate: 0.5, Burst: 3}
+
+	steps := []struct {
+		idle    time.Duration
+		tokens  float64
+		allowed bool
+	}{
+		{0, 2, true}, // a new bucket is full
+		{0, 1, true},
+		{0, 0, true},
+		{0, 0, false},
+		{time.Second, 0.5, false},
+		{time.Second, 0, true},
+		{time.Hour, 2, true}, // refilled up to the burst only
+	}
+	for i, s := range steps {
+		ageBucket(t, storage, key, s.idle)
+		tokens, allowed, err := store.Take(ctx, key, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the statements themselves take some time, which refills the bucket a little
+		if math.Abs(tokens-s.tokens) > 0.05 || allowed != s.allowed {
+			t.Errorf("step %d: Take() = %v, %t, want %v, %t", i, tokens, allowed, s.tokens, s.allowed)
+		}
+	}
+}
+
+func TestRateLimitCleanup(t *testing.T) {
+	storage := testStorage(t)
+	store := NewRateLimitStore(storage)
+	store.lastCleanup = time.Now()
+	ctx := context.Background()
+	fast := "test|" + uuid.NewString()
+	slow := "test|" + uuid.NewString()
+
+	for i := 0; i < 10; i++ {
+		if _, _, err := store.Take(ctx, fast, ratelimit.Limit{Rate: 1, Burst: 10}); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := store.Take(ctx, slow, ratelimit.Limit{Rate: 10.0 / 3600, Burst: 10}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// both are idle for longer than the fast rule takes to refill, but not the slow one
+	ageBucket(t, storage, fast, 10*time.Minute)
+	ageBucket(t, storage, slow, 10*time.Minute)
+	store.lastCleanup = time.Time{}
+	store.cleanup(ctx)
+
+	exists := func(key string) bool {
+		var found bool
+		err := storage.Pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM rate_limits WHERE key = $1);`, key).Scan(&found)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return found
+	}
+	if exists(fast) {
+		t.Error("the refilled bucket is kept")
+	}
+	if !exists(slow) {
+		t.Error("the bucket of the 10/h rule is removed before it is refilled")
+	}
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"user-service/pkg/requestid"
+
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	defaultApplicationName = "user-service"
+	maxApplicationName     = 63 // longer names are truncated by Postgres
+)
+
+// begin starts a transaction. If the context carries a request ID, application_name of the connection is
+// set to "<base> req:<request ID>" for the duration of the transaction, so that slow queries in the server
+// log (with %a in log_line_prefix) and pg_stat_activity can be matched with the request. The name is set
+// locally to the transaction and reverts on commit or rollback, so connections are not retagged on acquire.
+func (db *DBStorage) begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id := requestid.FromContext(ctx)
+	if id == "" {
+		return tx, nil
+	}
+	name := db.appName + " req:" + id
+	if len(name) > maxApplicationName {
+		name = name[:maxApplicationName]
+	}
+	if _, err = tx.Exec(ctx, `SELECT set_config('application_name', $1, true);`, name); err != nil {
+		tx.Rollback(ctx)
+		return nil, err
+	}
+	return tx, nil
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"time"
+	"user-service/internal/domain/models"
+)
+
+func (db *DBStorage) SaveSession(ctx context.Context, s models.Session) (err error) {
+	const query = `
+	INSERT INTO sessions (id, token_hash, username, amr, user_agent, ip, created_at, last_seen_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+	`
+	ctx, done := instrument(ctx, "SaveSession", query)
+	defer done(&err)
+	_, err = db.Pool.Exec(ctx, query, s.ID, s.TokenHash, s.Username, s.AMR, s.UserAgent, s.IP, s.CreatedAt, s.LastSeenAt, s.ExpiresAt)
+	return
+}
+
+// GetSessionByToken returns the session together with the current role of its user. Sessions of users
+// that are deleted or not active are not found.
+func (db *DBStorage) GetSessionByToken(ctx context.Context, tokenHash string) (s models.Session, err error) {
+	const query = `
+	SELECT s.id, u.id, s.username, u.role, s.amr, COALESCE(s.user_agent, ''), COALESCE(s.ip, ''), s.created_at, s.last_seen_at, s.expires_at
+	FROM sessions s JOIN users u ON u.username = s.username
+	WHERE s.token_hash = $1 AND u.deleted_at IS NULL AND u.status = 'active';
+	`
+	ctx, done := instrument(ctx, "GetSessionByToken", query)
+	defer done(&err)
+	err = db.Pool.QueryRow(ctx, query, tokenHash).
+		Scan(&s.ID, &s.UserID, &s.Username, &s.Role, &s.AMR, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt)
+	return
+}
+
+func (db *DBStorage) TouchSession(ctx context.Context, id string, expiresAt time.Time) (err error) {
+	const query = `
+	UPDATE sessions SET last_seen_at = now(), expires_at = $1 WHERE id = $2;
+	`
+	ctx, done := instrument(ctx, "TouchSession", query)
+	defer done(&err)
+	_, err = db.Pool.Exec(ctx, query, expiresAt, id)
+	return
+}
+
+// ListSessions returns the active sessions of the user, most recently used first. Expired sessions
+// of the user are removed.
+func (db *DBStorage) ListSessions(ctx context.Context, username string) (_ []models.Session, err error) {
+	ctx, done := instrument(ctx, "ListSessions", "")
+	defer done(&err)
+	if _, err := db.Pool.Exec(ctx, `DELETE FROM sessions WHERE username = $1 AND expires_at < now();`, username); err != nil {
+		return nil, err
+	}
+	const query = `
+	SELECT id, username, amr, COALESCE(user_agent, ''), COALESCE(ip, ''), created_at, last_seen_at, expires_at
+	FROM sessions WHERE username = $1 ORDER BY last_seen_at DESC;
+	`
+	rows, err := db.Pool.Query(ctx, query, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var s models.Session
+		err = rows.Scan(&s.ID, &s.Username, &s.AMR, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// DeleteSession removes the session of the user. It reports false if the user has no such session.
+func (db *DBStorage) DeleteSession(ctx context.Context, username, id string) (_ bool, err error) {
+	const query = `
+	DELETE FROM sessions WHERE username = $1 AND id = $2;
+	`
+	ctx, done := instrument(ctx, "DeleteSession", query)
+	defer done(&err)
+	tag, err := db.Pool.Exec(ctx, query, username, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
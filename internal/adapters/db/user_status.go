@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"user-service/internal/domain/models"
+
+	"github.com/jackc/pgx/v4"
+)
+
+func (db *DBStorage) GetUserStatus(ctx context.Context, username string) (status models.UserStatus, err error) {
+	const query = `
+	SELECT status, status_reason, status_changed_at FROM users WHERE username = $1 AND deleted_at IS NULL;
+	`
+	ctx, done := instrument(ctx, "GetUserStatus", query)
+	defer done(&err)
+	err = db.Pool.QueryRow(ctx, query, username).Scan(&status.Status, &status.Reason, &status.ChangedAt)
+	return
+}
+
+// SetUserStatus changes the status of the user if it is still from, and records the change in the audit log
+// in the same transaction. The sessions of users that are no longer active are ended. It reports false if
+// the user is not found or its status has changed concurrently.
+func (db *DBStorage) SetUserStatus(ctx context.Context, username, from, to, reason string) (_ bool, err error) {
+	const query = `
+	UPDATE users SET status = $3, status_reason = $4, status_changed_at = now(), updated_at = now()
+	WHERE username = $1 AND status = $2 AND deleted_at IS NULL
+	RETURNING id;
+	`
+	ctx, done := instrument(ctx, "SetUserStatus", query)
+	defer done(&err)
+	tx, err := db.begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id string
+	if err = tx.QueryRow(ctx, query, username, from, to, reason).Scan(&id); errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if to != models.UserStatusActive {
+		if _, err = tx.Exec(ctx, `DELETE FROM sessions WHERE username = $1;`, username); err != nil {
+			return false, err
+		}
+	}
+	changes := []models.AuditChange{{Field: "status", Old: &from, New: &to}}
+	if reason != "" {
+		changes = append(changes, models.AuditChange{Field: "status_reason", New: &reason})
+	}
+	if err = writeAudit(ctx, tx, id, username, models.AuditActionStatus, changes); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
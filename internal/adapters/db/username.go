@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"user-service/pkg/infra/logger"
+	"user-service/pkg/usernames"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// FindUsername returns the username, as stored, of the user whose username is the same as the given one
+// or has the same skeleton, i.e. differs only in case, Unicode form or lookalike characters. Deleted users
+// are found as well, as their usernames stay reserved.
+func (db *DBStorage) FindUsername(ctx context.Context, username string) (stored string, found bool, err error) {
+	const query = `
+	SELECT username FROM users WHERE username = $1 OR username_norm = $2
+	ORDER BY username = $1 DESC
+	LIMIT 1;
+	`
+	ctx, done := instrument(ctx, "FindUsername", query)
+	defer done(&err)
+	err = db.Pool.QueryRow(ctx, query, username, usernames.Skeleton(username)).Scan(&stored)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, nil
+	}
+	return stored, err == nil, err
+}
+
+// normalizeUsernames fills in the skeletons of the usernames stored before they were introduced. A user whose
+// username has the same skeleton as another user's is left without one and logged: it is still found by
+// its exact username, and it should be renamed.
+func (db *DBStorage) normalizeUsernames(ctx context.Context) error {
+	rows, err := db.Pool.Query(ctx, `SELECT id, username FROM users WHERE username_norm IS NULL ORDER BY created_at;`)
+	if err != nil {
+		return err
+	}
+	type user struct{ id, username string }
+	var users []user
+	for rows.Next() {
+		var u user
+		if err = rows.Scan(&u.id, &u.username); err != nil {
+			rows.Close()
+			return err
+		}
+		users = append(users, u)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	for _, u := range users {
+		_, err = db.Pool.Exec(ctx, `UPDATE users SET username_norm = $1 WHERE id = $2;`, usernames.Skeleton(u.username), u.id)
+		if pgErr := (*pgconn.PgError)(nil); errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			logger.Get().Warn("username conflicts with another user regardless of case or lookalike characters, rename the user",
+				"username", u.username, "id", u.id)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("normalizing username of user %s: %w", u.id, err)
+		}
+	}
+
+	rows, err = db.Pool.Query(ctx, `SELECT id, username FROM username_history WHERE username_norm IS NULL;`)
+	if err != nil {
+		return err
+	}
+	history := map[int64]string{}
+	for rows.Next() {
+		var id int64
+		var username string
+		if err = rows.Scan(&id, &username); err != nil {
+			rows.Close()
+			return err
+		}
+		history[id] = username
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	for id, username := range history {
+		if _, err = db.Pool.Exec(ctx, `UPDATE username_history SET username_norm = $1 WHERE id = $2;`, usernames.Skeleton(username), id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
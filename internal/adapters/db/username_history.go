@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+	"user-service/pkg/usernames"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// GetFormerUsernameOwner returns the current username of the user that most recently released the username,
+// or one with the same skeleton, by renaming after the time. Deleted users are skipped, so their former
+// usernames do not redirect to them.
+func (db *DBStorage) GetFormerUsernameOwner(ctx context.Context, username string, releasedAfter time.Time) (current string, found bool, err error) {
+	const query = `
+	SELECT u.username
+	FROM username_history h JOIN users u ON u.id = h.user_id AND u.deleted_at IS NULL
+	WHERE h.username_norm = $1 AND h.released_at > $2
+	ORDER BY h.released_at DESC
+	LIMIT 1;
+	`
+	ctx, done := instrument(ctx, "GetFormerUsernameOwner", query)
+	defer done(&err)
+	err = db.Pool.QueryRow(ctx, query, usernames.Skeleton(username), releasedAfter).Scan(&current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, nil
+	}
+	return current, err == nil, err
+}
+
+// PurgeUsernameHistory removes the usernames released before the time. It returns the number of removed entries.
+func (db *DBStorage) PurgeUsernameHistory(ctx context.Context, releasedBefore time.Time) (_ int64, err error) {
+	const query = `
+	DELETE FROM username_history WHERE released_at < $1;
+	`
+	ctx, done := instrument(ctx, "PurgeUsernameHistory", query)
+	defer done(&err)
+	tag, err := db.Pool.Exec(ctx, query, releasedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
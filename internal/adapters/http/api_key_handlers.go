@@ -0,0 +1,91 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"user-service/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @ID createAPIKey
+// @tags admin
+// @Summary Create API key
+// @Description Mints a new API key for service-to-service calls. The key is returned only in this response, the service keeps its hash. Services pass it in the 'Authorization: ApiKey <key>' header.
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept json
+// @Param request body models.CreateAPIKeyRequest true "key name, scopes and optional expiration time"
+// @Success 200 {object} models.CreateAPIKeyResponse "API key created."
+// @Failure 400 {object} models.ErrorResponse "Missing required parameters / unknown scope / expiration time in the past."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Access denied / two-factor authentication required."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /admin/api-keys [post]
+func (a *Adapter) createAPIKey(ctx *gin.Context) {
+	principal, _ := getPrincipal(ctx)
+	var req models.CreateAPIKeyRequest
+	err := ctx.BindJSON(&req)
+	if err != nil {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+	resp, err := a.apiKeySvc.CreateAPIKey(ctx.Request.Context(), principal.Username, req)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @ID listAPIKeys
+// @tags admin
+// @Summary List API keys
+// @Description Returns all API keys including revoked and expired ones, without the keys themselves.
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Success 200 {array} models.APIKey "API keys."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Access denied / two-factor authentication required."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /admin/api-keys [get]
+func (a *Adapter) listAPIKeys(ctx *gin.Context) {
+	keys, err := a.apiKeySvc.ListAPIKeys(ctx.Request.Context())
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, keys)
+}
+
+// @ID revokeAPIKey
+// @tags admin
+// @Summary Revoke API key
+// @Description Revokes the API key with the given id. Revoked keys are rejected immediately.
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "id of the API key to revoke"
+// @Success 200 {object} models.SuccessResponse "API key revoked."
+// @Failure 400 {object} models.ErrorResponse "Invalid 'id' parameter."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Access denied / two-factor authentication required."
+// @Failure 404 {object} models.ErrorResponse "API key not found."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /admin/api-keys/{id} [delete]
+func (a *Adapter) revokeAPIKey(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+	err = a.apiKeySvc.RevokeAPIKey(ctx.Request.Context(), id)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Success: fmt.Sprintf("api key with id '%d' revoked", id)},
+	)
+}
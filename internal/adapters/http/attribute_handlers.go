@@ -0,0 +1,83 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"user-service/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @ID listAttributeDefinitions
+// @tags admin
+// @Summary Custom user attributes
+// @Description Returns the definitions of the custom attributes users may have.
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Success 200 {array} models.AttributeDefinition "Attribute definitions."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Access denied / two-factor authentication required."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /admin/user-attributes [get]
+func (a *Adapter) listAttributeDefinitions(ctx *gin.Context) {
+	defs, err := a.userSvc.ListAttributeDefinitions(ctx.Request.Context())
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, defs)
+}
+
+// @ID setAttributeDefinition
+// @tags admin
+// @Summary Define custom user attribute
+// @Description Creates or replaces the definition of a custom attribute. Attribute values are validated against the JSON Schema when users are created or updated. Supported keywords: type, enum, const, minLength, maxLength, pattern, minimum, maximum, exclusiveMinimum, exclusiveMaximum, items, minItems, maxItems, properties, required, additionalProperties and annotations such as title and description; schemas with other keywords are rejected.
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept json
+// @Param name path string true "attribute name: lowercase letters, digits and '_', starting with a letter"
+// @Param request body models.SetAttributeDefinitionRequest true "JSON Schema of the values and description"
+// @Success 200 {object} models.AttributeDefinition "Attribute defined."
+// @Failure 400 {object} models.ErrorResponse "Invalid name or schema."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Access denied / two-factor authentication required."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /admin/user-attributes/{name} [put]
+func (a *Adapter) setAttributeDefinition(ctx *gin.Context) {
+	var req models.SetAttributeDefinitionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+	def, err := a.userSvc.SetAttributeDefinition(ctx.Request.Context(), ctx.Param("name"), req)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, def)
+}
+
+// @ID deleteAttributeDefinition
+// @tags admin
+// @Summary Delete custom user attribute
+// @Description Deletes the definition of a custom attribute and removes the attribute from all users. The removal is recorded in the audit log of each user.
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param name path string true "attribute name"
+// @Success 200 {object} models.SuccessResponse "Attribute deleted."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Access denied / two-factor authentication required."
+// @Failure 404 {object} models.ErrorResponse "Attribute definition not found."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /admin/user-attributes/{name} [delete]
+func (a *Adapter) deleteAttributeDefinition(ctx *gin.Context) {
+	name := ctx.Param("name")
+	if err := a.userSvc.DeleteAttributeDefinition(ctx.Request.Context(), name); err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Success: fmt.Sprintf("attribute '%s' deleted", name)},
+	)
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"user-service/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @ID getUserAudit
+// @tags admin
+// @Summary User audit log
+// @Description Returns the changes of the user (create, update, delete), newest first: who made each change, the request ID, IP address and the changed fields. Password values are never included. The log is kept after the user is deleted.
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param username path string true "username of the user"
+// @Param limit query int false "page size, 50 by default, at most 200"
+// @Param cursor query int false "'next_cursor' of the previous page"
+// @Success 200 {object} models.AuditPage "Audit log entries."
+// @Failure 400 {object} models.ErrorResponse "Invalid 'limit' or 'cursor' parameter."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Access denied / two-factor authentication required."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /user/{username}/audit [get]
+func (a *Adapter) getUserAudit(ctx *gin.Context) {
+	username, err := a.getUsernameFromPath(ctx)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	var (
+		limit  int
+		cursor int64
+	)
+	if s := ctx.Query("limit"); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit < 0 {
+			a.ErrorHandler(ctx, models.ErrBadRequest)
+			return
+		}
+	}
+	if s := ctx.Query("cursor"); s != "" {
+		if cursor, err = strconv.ParseInt(s, 10, 64); err != nil || cursor < 0 {
+			a.ErrorHandler(ctx, models.ErrBadRequest)
+			return
+		}
+	}
+	page, err := a.userSvc.GetUserAudit(ctx.Request.Context(), username, cursor, limit)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"net/http"
+	"user-service/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @ID login
+// @tags auth
+// @Summary Log in
+// @Description Checks the username and password. If two-factor authentication is enabled for the user, returns 'mfa_required' with a short-lived 'mfa_token' that must be exchanged at /auth/login/2fa, otherwise returns an access token. In session mode, sets the HttpOnly session cookie and the CSRF cookie instead of returning the access token.
+// @Accept json
+// @Param credentials body models.LoginRequest true "username and password"
+// @Success 200 {object} models.LoginResponse "Access token, second step token or CSRF token of the new session."
+// @Failure 400 {object} models.ErrorResponse "Missing required parameters."
+// @Failure 401 {object} models.ErrorResponse "Invalid username or password."
+// @Failure 403 {object} models.ErrorResponse "User account is not active (pending, suspended or deactivated)."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Failure 429 {object} models.ErrorResponse "Too many requests, see the RateLimit-* and Retry-After headers."
+// @Router /auth/login [post]
+func (a *Adapter) login(ctx *gin.Context) {
+	var req models.LoginRequest
+	err := ctx.BindJSON(&req)
+	if err != nil || req.Username == "" || req.Password == "" {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+	resp, err := a.authSvc.Login(ctx.Request.Context(), req)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	a.respondLogin(ctx, resp)
+}
+
+// @ID loginSecondFactor
+// @tags auth
+// @Summary Complete login with second factor
+// @Description Exchanges the 'mfa_token' from /auth/login and a TOTP code (or an unused recovery code) for an access token, or for a session cookie in session mode.
+// @Accept json
+// @Param request body models.LoginSecondFactorRequest true "second step token and code"
+// @Success 200 {object} models.LoginResponse "Access token or CSRF token of the new session."
+// @Failure 400 {object} models.ErrorResponse "Missing required parameters."
+// @Failure 401 {object} models.ErrorResponse "Invalid or expired token / invalid one-time code."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Failure 429 {object} models.ErrorResponse "Too many requests, see the RateLimit-* and Retry-After headers."
+// @Router /auth/login/2fa [post]
+func (a *Adapter) loginSecondFactor(ctx *gin.Context) {
+	var req models.LoginSecondFactorRequest
+	err := ctx.BindJSON(&req)
+	if err != nil || req.MFAToken == "" || req.Code == "" {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+	resp, err := a.authSvc.LoginSecondFactor(ctx.Request.Context(), req)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	a.respondLogin(ctx, resp)
+}
+
+// @ID enrollTOTP
+// @tags auth
+// @Summary Start two-factor authentication enrollment
+// @Description Generates a new TOTP secret for the current user and returns it with an otpauth:// URI and a QR code (base64-encoded PNG) for authenticator apps. 2FA is enabled only after confirmation.
+// @Security BearerAuth
+// @Success 200 {object} models.TOTPEnrollResponse "Secret generated."
+// @Failure 400 {object} models.ErrorResponse "Two-factor authentication already enabled."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /auth/2fa/enroll [post]
+func (a *Adapter) enrollTOTP(ctx *gin.Context) {
+	principal, _ := getPrincipal(ctx)
+	resp, err := a.authSvc.EnrollTOTP(ctx.Request.Context(), principal.Username)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @ID confirmTOTP
+// @tags auth
+// @Summary Confirm two-factor authentication
+// @Description Enables 2FA for the current user after checking a code from the enrolled secret. Returns recovery codes, which are shown only once.
+// @Security BearerAuth
+// @Accept json
+// @Param request body models.TOTPCodeRequest true "code from the authenticator app"
+// @Success 200 {object} models.TOTPConfirmResponse "2FA enabled."
+// @Failure 400 {object} models.ErrorResponse "Missing required parameters / 2FA not enrolled / already enabled."
+// @Failure 401 {object} models.ErrorResponse "Authentication required / invalid one-time code."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /auth/2fa/confirm [post]
+func (a *Adapter) confirmTOTP(ctx *gin.Context) {
+	principal, _ := getPrincipal(ctx)
+	var req models.TOTPCodeRequest
+	err := ctx.BindJSON(&req)
+	if err != nil || req.Code == "" {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+	resp, err := a.authSvc.ConfirmTOTP(ctx.Request.Context(), principal.Username, req.Code)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @ID disableTOTP
+// @tags auth
+// @Summary Disable two-factor authentication
+// @Description Disables 2FA for the current user after checking a TOTP or recovery code. Remaining recovery codes are removed.
+// @Security BearerAuth
+// @Accept json
+// @Param request body models.TOTPCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} models.SuccessResponse "2FA disabled."
+// @Failure 400 {object} models.ErrorResponse "Missing required parameters / 2FA not enabled."
+// @Failure 401 {object} models.ErrorResponse "Authentication required / invalid one-time code."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /auth/2fa [delete]
+func (a *Adapter) disableTOTP(ctx *gin.Context) {
+	principal, _ := getPrincipal(ctx)
+	var req models.TOTPCodeRequest
+	err := ctx.BindJSON(&req)
+	if err != nil || req.Code == "" {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+	err = a.authSvc.DisableTOTP(ctx.Request.Context(), principal.Username, req.Code)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Success: "two-factor authentication disabled"},
+	)
+}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"user-service/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead allows for the multipart envelope of the avatar file in the request body.
+const multipartOverhead = 64 << 10
+
+// Cache-Control of avatars: the URLs with the current version never change, the others are revalidated
+// with the ETag.
+const (
+	avatarCacheVersioned = "public, max-age=31536000, immutable"
+	avatarCacheCurrent   = "public, no-cache"
+)
+
+// @ID setAvatar
+// @tags user
+// @Summary Upload avatar
+// @Description Replaces the user's avatar with the uploaded JPEG, PNG or GIF image (the first frame of animations). The image is cropped to a square and scaled to the thumbnail sizes; EXIF and other metadata of the file are not kept. Users may change their own avatar only; administrators and API keys may change any. The file is limited to AVATAR_MAX_SIZE bytes.
+// @Accept mpfd
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param username path string true "username of the user"
+// @Param avatar formData file true "image file"
+// @Success 200 {object} models.Avatar "Avatar uploaded."
+// @Failure 400 {object} models.ErrorResponse "Missing 'avatar' file."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Users may change their own avatar only."
+// @Failure 404 {object} models.ErrorResponse "User with given username not found."
+// @Failure 413 {object} models.ErrorResponse "File or image dimensions too large."
+// @Failure 415 {object} models.ErrorResponse "File is not a JPEG, PNG or GIF image."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /user/{username}/avatar [put]
+func (a *Adapter) setAvatar(ctx *gin.Context) {
+	username, err := a.getUsernameFromPath(ctx)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, a.opts.AvatarMaxSize+multipartOverhead)
+	header, err := ctx.FormFile("avatar")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			a.ErrorHandler(ctx, models.ErrAvatarTooLarge)
+			return
+		}
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+	if header.Size > a.opts.AvatarMaxSize {
+		a.ErrorHandler(ctx, models.ErrAvatarTooLarge)
+		return
+	}
+	f, err := header.Open()
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	// the declared content type of the part is not trusted
+	switch http.DetectContentType(data) {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		a.ErrorHandler(ctx, models.ErrUnsupportedAvatar)
+		return
+	}
+	avatar, err := a.avatarSvc.SetAvatar(ctx.Request.Context(), username, data)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, avatar)
+}
+
+// @ID getAvatar
+// @tags user
+// @Summary Get avatar
+// @Description Returns the thumbnail of the user's avatar. Responses to the URL with the current version ('avatar_url' of the user) may be cached forever, the others are revalidated with the ETag.
+// @Produce jpeg
+// @Produce png
+// @Param username path string true "username of the user"
+// @Param size query int false "thumbnail size in pixels: 512, 256, 128 or 64" default(256)
+// @Param v query string false "avatar version"
+// @Success 200 {file} file "Avatar image."
+// @Success 304 "Not modified."
+// @Failure 400 {object} models.ErrorResponse "Unknown size."
+// @Failure 404 {object} models.ErrorResponse "User with given username not found / user has no avatar."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /user/{username}/avatar [get]
+func (a *Adapter) getAvatar(ctx *gin.Context) {
+	username, err := a.getUsernameFromPath(ctx)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	var size int
+	if s := ctx.Query("size"); s != "" {
+		if size, err = strconv.Atoi(s); err != nil {
+			a.ErrorHandler(ctx, models.ErrInvalidAvatarSize)
+			return
+		}
+	}
+	img, err := a.avatarSvc.GetAvatar(ctx.Request.Context(), username, size)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	defer img.Content.Close()
+
+	h := ctx.Writer.Header()
+	h.Set("Content-Type", img.ContentType)
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Set("ETag", `"`+img.Version+`"`)
+	if ctx.Query("v") == img.Version {
+		h.Set("Cache-Control", avatarCacheVersioned)
+	} else {
+		h.Set("Cache-Control", avatarCacheCurrent)
+	}
+	// handles conditional and range requests
+	http.ServeContent(ctx.Writer, ctx.Request, "", img.ModTime, img.Content)
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"net/http"
+	"user-service/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @ID getConfigReload
+// @tags admin
+// @Summary Last config reload
+// @Description Returns the result of the last reload of the runtime settings (log level, rate limits, CORS origins), triggered by SIGHUP, a change of the config file or an admin request.
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Success 200 {object} models.ConfigReloadResult "Result of the last reload."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Access denied / two-factor authentication required."
+// @Failure 404 {object} models.ErrorResponse "Config has not been reloaded yet."
+// @Router /admin/config/reload [get]
+func (a *Adapter) getConfigReload(ctx *gin.Context) {
+	res, err := a.configSvc.LastConfigReload()
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+// @ID reloadConfig
+// @tags admin
+// @Summary Reload config
+// @Description Reloads the configuration from its sources and applies the settings that can be changed without restart. Other changed settings are listed in 'requires_restart'. If the new configuration is invalid, nothing is applied.
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Success 200 {object} models.ConfigReloadResult "Settings reloaded."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Access denied / two-factor authentication required."
+// @Failure 422 {object} models.ConfigReloadResult "Invalid configuration, nothing applied."
+// @Router /admin/config/reload [post]
+func (a *Adapter) reloadConfig(ctx *gin.Context) {
+	res := a.configSvc.ReloadConfig(ctx.Request.Context(), models.ReloadTriggerAPI)
+	status := http.StatusOK
+	if !res.Success {
+		status = http.StatusUnprocessableEntity
+	}
+	ctx.JSON(status, res)
+}
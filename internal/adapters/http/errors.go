@@ -5,29 +5,53 @@ import (
 	"net/http"
 	"user-service/internal/domain/models"
 	"user-service/pkg/infra/logger"
+	"user-service/pkg/requestid"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func (a *Adapter) ErrorHandler(ctx *gin.Context, err error) {
-	logger.Get().Warn("request failed: ", "desc", err.Error())
+	logger.FromContext(ctx.Request.Context()).Warn("request failed: ", "desc", err.Error())
+	trace.SpanFromContext(ctx.Request.Context()).RecordError(err)
 
+	var status int
 	switch {
 	case errors.Is(err, models.ErrInvalidEmailFormat), errors.Is(err, models.ErrInvalidPhoneFormat),
-		errors.Is(err, models.ErrUserAlreadyExists), errors.Is(err, models.ErrBadRequest):
-		ctx.JSON(
-			http.StatusBadRequest,
-			models.ErrorResponse{Error: err.Error()},
-		)
-	case errors.Is(err, models.ErrUserNotFound):
-		ctx.JSON(
-			http.StatusNotFound,
-			models.ErrorResponse{Error: err.Error()},
-		)
+		errors.Is(err, models.ErrUserAlreadyExists), errors.Is(err, models.ErrBadRequest),
+		errors.Is(err, models.ErrTOTPAlreadyEnabled), errors.Is(err, models.ErrTOTPNotEnrolled),
+		errors.Is(err, models.ErrInvalidScope), errors.Is(err, models.ErrInvalidRedirectURI),
+		errors.Is(err, models.ErrInvalidLogLevel), errors.Is(err, models.ErrInvalidUserStatus),
+		errors.Is(err, models.ErrStatusReasonRequired), errors.Is(err, models.ErrInvalidUsername),
+		errors.Is(err, models.ErrInvalidAttributes), errors.Is(err, models.ErrInvalidAttributeName),
+		errors.Is(err, models.ErrInvalidAttributeSchema), errors.Is(err, models.ErrInvalidPreferences),
+		errors.Is(err, models.ErrInvalidAvatarSize):
+		status = http.StatusBadRequest
+	case errors.Is(err, models.ErrUnauthorized), errors.Is(err, models.ErrInvalidCredentials),
+		errors.Is(err, models.ErrInvalidOTP):
+		status = http.StatusUnauthorized
+	case errors.Is(err, models.ErrForbidden), errors.Is(err, models.ErrMFARequired),
+		errors.Is(err, models.ErrInvalidCSRFToken), errors.Is(err, models.ErrUserNotActive):
+		status = http.StatusForbidden
+	case errors.Is(err, models.ErrUserNotFound), errors.Is(err, models.ErrAPIKeyNotFound),
+		errors.Is(err, models.ErrOAuthClientNotFound), errors.Is(err, models.ErrSessionNotFound),
+		errors.Is(err, models.ErrNoConfigReload), errors.Is(err, models.ErrAttributeNotFound),
+		errors.Is(err, models.ErrAvatarNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, models.ErrStatusTransition), errors.Is(err, models.ErrUsernameReserved),
+		errors.Is(err, models.ErrUsernameConfusable), errors.Is(err, models.ErrUsernameRenameRequired):
+		status = http.StatusConflict
+	case errors.Is(err, models.ErrAvatarTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, models.ErrUnsupportedAvatar):
+		status = http.StatusUnsupportedMediaType
+	case errors.Is(err, models.ErrTooManyRequests):
+		status = http.StatusTooManyRequests
 	default:
-		ctx.JSON(
-			http.StatusInternalServerError,
-			models.ErrorResponse{Error: err.Error()},
-		)
+		status = http.StatusInternalServerError
 	}
+	ctx.JSON(status, models.ErrorResponse{
+		Error:     err.Error(),
+		RequestID: requestid.FromContext(ctx.Request.Context()),
+	})
 }
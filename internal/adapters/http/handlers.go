@@ -1,13 +1,15 @@
 package http
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
 	"user-service/internal/domain/models"
-	"user-service/pkg/infra/logger"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
 )
 
 // @ID health
@@ -21,15 +23,44 @@ func (a *Adapter) health(ctx *gin.Context) {
 	)
 }
 
+// @ID livez
+// @Summary Liveness probe
+// @Description Reports that the process is running and able to serve requests. Does not check dependencies.
+// @Success 200 {object} models.HealthResponse "Service is alive."
+// @Router /livez [get]
+func (a *Adapter) livez(ctx *gin.Context) {
+	ctx.JSON(
+		http.StatusOK,
+		models.HealthResponse{Status: models.HealthStatusUp},
+	)
+}
+
+// @ID readyz
+// @Summary Readiness probe
+// @Description Checks the database connection and that all migrations are applied, and reports the status and latency of each dependency. Fails while the service is shutting down, so that load balancers stop routing requests to it.
+// @Success 200 {object} models.HealthResponse "Service is ready."
+// @Failure 503 {object} models.HealthResponse "A dependency is unavailable or the service is shutting down."
+// @Router /readyz [get]
+func (a *Adapter) readyz(ctx *gin.Context) {
+	resp := a.healthSvc.Ready(ctx.Request.Context())
+	status := http.StatusOK
+	if resp.Status != models.HealthStatusUp {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, resp)
+}
+
 // @ID createUser
 // @tags user
 // @Summary Create user
-// @Description Creates a new user with given data. Checks that email and phone are in the correct format, and that the user with given username is not yet in the database, otherwise it returns the BadRequest status.
+// @Description Creates a new user with given data and returns its generated ID, which does not change when the username does. Checks that email and phone are in the correct format, and that the user with given username is not yet in the database, otherwise it returns the BadRequest status.
 // @Accept json
 // @Param user body models.User true "user data"
-// @Success 200 {object} models.SuccessResponse "User created successfully."
-// @Failure 400 {object} models.ErrorResponse "User already exists / missing required 'user' parameter / invalid format of 'email' or 'phone' parameters."
+// @Success 200 {object} models.CreateUserResponse "User created successfully."
+// @Failure 400 {object} models.ErrorResponse "User already exists, also with the username in another case / missing required 'user' parameter / invalid username / invalid format of 'email' or 'phone' parameters / undefined or invalid attributes."
+// @Failure 409 {object} models.ErrorResponse "Username is confusable with an existing one / was recently released by another user."
 // @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Failure 429 {object} models.ErrorResponse "Too many requests, see the RateLimit-* and Retry-After headers."
 // @Router /user [post]
 func (a *Adapter) createUser(ctx *gin.Context) {
 	var user models.User
@@ -48,24 +79,28 @@ func (a *Adapter) createUser(ctx *gin.Context) {
 		a.ErrorHandler(ctx, models.ErrInvalidPhoneFormat)
 		return
 	}
-	err = a.userSvc.CreateUser(user)
+	id, err := a.userSvc.CreateUser(ctx.Request.Context(), user)
 	if err != nil {
 		a.ErrorHandler(ctx, err)
 		return
 	}
 	ctx.JSON(
 		http.StatusOK,
-		models.SuccessResponse{Success: fmt.Sprintf("user with username '%s' created", user.Username)},
+		models.CreateUserResponse{Success: fmt.Sprintf("user with username '%s' created", user.Username), ID: id},
 	)
 }
 
 // @ID deleteUser
 // @tags user
 // @Summary Delete user
-// @Description Deletes the user with given username.
+// @Description Deletes the user with given username and ends the user's sessions. The user can be restored by an administrator until it is permanently removed after the retention period (DELETED_USER_RETENTION); the username stays reserved until then.
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param username path string true "username of the user to delete."
 // @Success 200 {object} models.SuccessResponse "User deleted successfully."
 // @Failure 400 {object} models.ErrorResponse "Missing required 'username' parameter."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Users may delete their own account only / API key without the users:write scope."
 // @Failure 404 {object} models.ErrorResponse "User with given username not found."
 // @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
 // @Router /user/{username} [delete]
@@ -75,7 +110,7 @@ func (a *Adapter) deleteUser(ctx *gin.Context) {
 		a.ErrorHandler(ctx, models.ErrBadRequest)
 		return
 	}
-	err = a.userSvc.DeleteUser(username)
+	err = a.userSvc.DeleteUser(ctx.Request.Context(), username)
 	if err != nil {
 		a.ErrorHandler(ctx, err)
 		return
@@ -86,16 +121,51 @@ func (a *Adapter) deleteUser(ctx *gin.Context) {
 	)
 }
 
+// @ID restoreUser
+// @tags admin
+// @Summary Restore user
+// @Description Restores a deleted user that has not been permanently removed yet.
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param username path string true "username of the user to restore"
+// @Success 200 {object} models.SuccessResponse "User restored successfully."
+// @Failure 400 {object} models.ErrorResponse "Missing required 'username' parameter."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Access denied / two-factor authentication required."
+// @Failure 404 {object} models.ErrorResponse "Deleted user with given username not found."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /user/{username}/restore [post]
+func (a *Adapter) restoreUser(ctx *gin.Context) {
+	username, err := a.getUsernameFromPath(ctx)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	if err = a.userSvc.RestoreUser(ctx.Request.Context(), username); err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Success: fmt.Sprintf("user with username '%s' restored", username)},
+	)
+}
+
 // @ID updateUser
 // @tags user
 // @Summary Update user
-// @Description Updates user data with given username.
+// @Description Updates user data with given username. If the username is changed, the former one redirects to the user for USERNAME_REDIRECT_PERIOD and cannot be taken by other users for USERNAME_REUSE_PERIOD. Custom attributes are replaced as a whole. The password is kept if it is omitted or empty.
 // @Accept json
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param username path string true "username of the user to update"
 // @Param user body models.User true "user data"
 // @Success 200 {object} models.SuccessResponse "User information updated successfully."
-// @Failure 400 {object} models.ErrorResponse "Missing required 'username' or 'user' parameters."
+// @Failure 400 {object} models.ErrorResponse "Missing required 'username' or 'user' parameters / undefined or invalid attributes."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Users may change their own account only / users that are not active can not change their own account."
 // @Failure 404 {object} models.ErrorResponse "User with given username not found."
+// @Failure 409 {object} models.ErrorResponse "New username is confusable with an existing one / was recently released by another user / conflicts with another user after normalization, choose another username."
 // @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
 // @Router /user/{username} [put]
 func (a *Adapter) updateUser(ctx *gin.Context) {
@@ -110,7 +180,7 @@ func (a *Adapter) updateUser(ctx *gin.Context) {
 		a.ErrorHandler(ctx, models.ErrBadRequest)
 		return
 	}
-	err = a.userSvc.UpdateUser(username, user)
+	err = a.userSvc.UpdateUser(ctx.Request.Context(), username, user)
 	if err != nil {
 		a.ErrorHandler(ctx, err)
 		return
@@ -124,10 +194,16 @@ func (a *Adapter) updateUser(ctx *gin.Context) {
 // @ID getUser
 // @tags user
 // @Summary Get user
-// @Description Returns information about the user with the given username.
+// @Description Returns information about the user with the given username, compared regardless of case and lookalike characters. A former username of a renamed user redirects to the current one for USERNAME_REDIRECT_PERIOD.
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param username path string true "Username of the user to get"
 // @Success 200 {object} models.GetUserResponse "User data received successfully."
+// @Success 307 "The user was renamed, see the Location header."
+// @Header 307 {string} Location "path of the user with the current username"
 // @Failure 400 {object} models.ErrorResponse "Missing required 'username' parameter."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "API key without the users:read scope."
 // @Failure 404 {object} models.ErrorResponse "User with given username not found."
 // @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
 // @Router /user/{username} [get]
@@ -137,9 +213,20 @@ func (a *Adapter) getUser(ctx *gin.Context) {
 		a.ErrorHandler(ctx, err)
 		return
 	}
-	user, err := a.userSvc.GetUser(username)
+	user, err := a.userSvc.GetUser(ctx.Request.Context(), username)
+	if errors.Is(err, models.ErrUserNotFound) || errors.Is(err, pgx.ErrNoRows) {
+		// the redirect is temporary: the former username may be taken by another user later
+		current, err := a.userSvc.ResolveFormerUsername(ctx.Request.Context(), username)
+		if err != nil {
+			a.ErrorHandler(ctx, err)
+			return
+		}
+		location := url.URL{Path: "/user/" + current, RawQuery: ctx.Request.URL.RawQuery}
+		ctx.Redirect(http.StatusTemporaryRedirect, location.String())
+		return
+	}
 	if err != nil {
-		a.ErrorHandler(ctx, models.ErrUserNotFound)
+		a.ErrorHandler(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, user)
@@ -147,9 +234,12 @@ func (a *Adapter) getUser(ctx *gin.Context) {
 
 func (a *Adapter) getUsernameFromPath(ctx *gin.Context) (string, error) {
 	username := ctx.Param("username")
-	logger.Get().Debug("got parameter from path", "username", username)
 	if username == ":username" {
 		return "", models.ErrBadRequest
 	}
+	// usernames differing only in case or lookalike characters refer to the same user
+	if stored, err := a.userSvc.ResolveUsername(ctx.Request.Context(), username); err == nil {
+		return stored, nil
+	}
 	return username, nil
 }
@@ -3,28 +3,65 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
-	"sync"
+	"sync/atomic"
 	"time"
 	"user-service/internal/ports"
 	"user-service/pkg/infra/logger"
+	"user-service/pkg/ratelimit"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/sync/errgroup"
 )
 
 type Adapter struct {
-	s       *http.Server
-	l       net.Listener
-	userSvc ports.UserService
+	s              *http.Server
+	l              net.Listener
+	errc           chan error
+	opts           AdapterOptions
+	userSvc        ports.UserService
+	authSvc        ports.AuthService
+	apiKeySvc      ports.APIKeyService
+	oidcSvc        ports.OIDCService
+	sessionSvc     ports.SessionService
+	healthSvc      ports.HealthService
+	configSvc      ports.ConfigService
+	preferencesSvc ports.PreferencesService
+	avatarSvc      ports.AvatarService
+	cors           atomic.Pointer[gin.HandlerFunc] // replaced when the allowed origins change
+}
+
+// Services groups the domain services used by the handlers.
+type Services struct {
+	User        ports.UserService
+	Auth        ports.AuthService
+	APIKey      ports.APIKeyService
+	OIDC        ports.OIDCService
+	Session     ports.SessionService
+	Health      ports.HealthService
+	Config      ports.ConfigService
+	Preferences ports.PreferencesService
+	Avatar      ports.AvatarService
 }
 
 type AdapterOptions struct {
-	HTTP_port   int
-	Timeout     time.Duration
-	IdleTimeout time.Duration
+	HTTP_port       int
+	Timeout         time.Duration
+	IdleTimeout     time.Duration
+	AdminRequireMFA bool
+
+	AuthMode            string // models.AuthModeToken or models.AuthModeSession
+	SessionMaxLifetime  time.Duration
+	SessionCookieSecure bool
+
+	RateLimiter *ratelimit.Limiter // nil disables rate limiting
+	CORSOrigins []string           // "*" allows any origin
+
+	TrustedProxies []string // IPs or CIDRs of reverse proxies whose X-Forwarded-For is trusted, none if empty
+
+	AvatarMaxSize int64 // bytes of uploaded avatar files
 }
 
 var router *gin.Engine
@@ -34,13 +71,19 @@ func GetRouter() *gin.Engine {
 }
 
 // New instantiates the adapter.
-func New(userService ports.UserService, opts AdapterOptions) (*Adapter, error) {
+func New(services Services, opts AdapterOptions) (*Adapter, error) {
 	l, err := net.Listen("tcp", fmt.Sprintf(":%d", opts.HTTP_port))
 	if err != nil {
 		return nil, fmt.Errorf("server start failed: %w", err)
 	}
 
-	router = gin.Default()
+	// gin.Default would add gin.Logger, which logs the raw paths; requests are logged by logRequest
+	router = gin.New()
+	router.Use(gin.Recovery())
+	if err = router.SetTrustedProxies(opts.TrustedProxies); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
 	server := http.Server{
 		Handler:      router,
 		ReadTimeout:  opts.Timeout,
@@ -48,36 +91,44 @@ func New(userService ports.UserService, opts AdapterOptions) (*Adapter, error) {
 		IdleTimeout:  opts.IdleTimeout, // client connection lifetime
 	}
 	a := Adapter{
-		s:       &server,
-		l:       l,
-		userSvc: userService,
+		s:              &server,
+		l:              l,
+		errc:           make(chan error, 1),
+		opts:           opts,
+		userSvc:        services.User,
+		authSvc:        services.Auth,
+		apiKeySvc:      services.APIKey,
+		oidcSvc:        services.OIDC,
+		sessionSvc:     services.Session,
+		healthSvc:      services.Health,
+		configSvc:      services.Config,
+		preferencesSvc: services.Preferences,
+		avatarSvc:      services.Avatar,
 	}
+	a.SetCORSOrigins(opts.CORSOrigins)
 	err = initRouter(&a, router)
 	return &a, err
 }
 
-// Start starts an http server that accepts incoming connections on the Listener.
+// Start starts an http server that accepts incoming connections on the Listener in the background.
+// If serving fails, the error is sent to the channel returned by Err.
 func (a *Adapter) Start() error {
-	logger.Get().Info("starting http server...")
-
-	eg := &errgroup.Group{}
-	eg.Go(func() error {
-		return a.s.Serve(a.l)
-	})
-	if err := eg.Wait(); err != nil {
-		return err
-	}
+	logger.Get().Info("starting http server...", "addr", a.l.Addr().String())
+
+	go func() {
+		if err := a.s.Serve(a.l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			a.errc <- err
+		}
+	}()
 	return nil
 }
 
-// Stop stops the http server.
+// Err returns the channel receiving the error if the server stops serving unexpectedly.
+func (a *Adapter) Err() <-chan error {
+	return a.errc
+}
+
+// Stop stops the http server. The application stops each component once.
 func (a *Adapter) Stop(ctx context.Context) error {
-	var (
-		err  error
-		once sync.Once
-	)
-	once.Do(func() {
-		err = a.s.Shutdown(ctx)
-	})
-	return err
+	return a.s.Shutdown(ctx)
 }
@@ -0,0 +1,44 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestStartDoesNotBlock(t *testing.T) {
+	a, err := New(Services{}, AdapterOptions{Timeout: time.Second, IdleTimeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan error, 1)
+	go func() { started <- a.Start() }()
+	select {
+	case err = <-started:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Start blocks while the server is serving")
+	}
+
+	// the server accepts requests in the background
+	resp, err := http.Get("http://" + a.l.Addr().String() + "/no/such/route")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %s, want 404", resp.Status)
+	}
+
+	if err = a.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err = <-a.Err():
+		t.Errorf("serving failed: %v", err)
+	default:
+	}
+}
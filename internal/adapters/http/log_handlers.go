@@ -0,0 +1,48 @@
+package http
+
+import (
+	"net/http"
+	"user-service/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @ID getLogLevel
+// @tags admin
+// @Summary Log level
+// @Description Returns the current minimum level of the messages written to the log.
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Success 200 {object} models.LogLevel "Current log level."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Access denied / two-factor authentication required."
+// @Router /admin/log-level [get]
+func (a *Adapter) getLogLevel(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, a.configSvc.LogLevel())
+}
+
+// @ID setLogLevel
+// @tags admin
+// @Summary Change log level
+// @Description Changes the log level without restart. The level is kept until LOG_LEVEL is changed in the config or the service is restarted.
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param request body models.LogLevel true "New log level: debug, info, warn or error."
+// @Success 200 {object} models.LogLevel "Log level changed."
+// @Failure 400 {object} models.ErrorResponse "Invalid log level."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Access denied / two-factor authentication required."
+// @Router /admin/log-level [put]
+func (a *Adapter) setLogLevel(ctx *gin.Context) {
+	var req models.LogLevel
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		a.ErrorHandler(ctx, models.ErrInvalidLogLevel)
+		return
+	}
+	res, err := a.configSvc.SetLogLevel(ctx.Request.Context(), req.Level)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"user-service/pkg/infra/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scrape returns the samples served by the metrics endpoint, keyed by the metric name with labels.
+func scrape(t *testing.T, router http.Handler) (map[string]float64, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics: %d", rec.Code)
+	}
+	samples := map[string]float64{}
+	scanner := bufio.NewScanner(strings.NewReader(rec.Body.String()))
+	for scanner.Scan() {
+		line := scanner.Text()
+		i := strings.LastIndexByte(line, ' ')
+		if strings.HasPrefix(line, "#") || i < 0 {
+			continue
+		}
+		if value, err := strconv.ParseFloat(line[i+1:], 64); err == nil {
+			samples[line[:i]] = value
+		}
+	}
+	return samples, rec.Body.String()
+}
+
+func TestMeasureRecordsRoutePattern(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a := &Adapter{}
+	router := gin.New()
+	router.Use(a.measure)
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	router.GET("/user/:username", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	router.DELETE("/user/:username", func(ctx *gin.Context) { ctx.Status(http.StatusNotFound) })
+
+	before, _ := scrape(t, router)
+	for _, req := range []struct{ method, path string }{
+		{http.MethodGet, "/user/IvanIvanov2000"},
+		{http.MethodGet, "/user/PetrPetrov1990"},
+		{http.MethodDelete, "/user/IvanIvanov2000"},
+		{http.MethodGet, "/no/such/route"},
+	} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req.method, req.path, nil))
+	}
+	after, scraped := scrape(t, router)
+
+	for _, want := range []string{
+		"# TYPE user_service_http_requests_total counter",
+		"# TYPE user_service_http_request_duration_seconds histogram",
+		"# TYPE user_service_http_requests_in_flight gauge",
+		"# TYPE user_service_users_created_total counter",
+		"# TYPE user_service_users_updated_total counter",
+		"# TYPE user_service_users_deleted_total counter",
+	} {
+		if !strings.Contains(scraped, want+"\n") {
+			t.Errorf("metrics do not contain %q", want)
+		}
+	}
+	for sample, want := range map[string]float64{
+		`user_service_http_requests_total{method="GET",route="/user/:username",status="200"}`:                            2,
+		`user_service_http_requests_total{method="DELETE",route="/user/:username",status="404"}`:                         1,
+		`user_service_http_requests_total{method="GET",route="unmatched",status="404"}`:                                  1,
+		`user_service_http_requests_total{method="GET",route="/metrics",status="200"}`:                                   1, // the first scrape
+		`user_service_http_request_duration_seconds_count{method="GET",route="/user/:username",status="200"}`:            2,
+		`user_service_http_request_duration_seconds_bucket{method="GET",route="/user/:username",status="200",le="+Inf"}`: 2,
+	} {
+		if got := after[sample] - before[sample]; got != want {
+			t.Errorf("%s increased by %v, want %v", sample, got, want)
+		}
+	}
+	if got := after["user_service_http_requests_in_flight"]; got != 1 { // the scrape itself
+		t.Errorf("user_service_http_requests_in_flight = %v, want 1", got)
+	}
+	for _, path := range []string{"IvanIvanov2000", "PetrPetrov1990", "/no/such/route"} {
+		if strings.Contains(scraped, path) {
+			t.Errorf("metrics contain the requested path %q instead of the route pattern", path)
+		}
+	}
+}
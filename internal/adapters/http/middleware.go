@@ -0,0 +1,347 @@
+package http
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"user-service/internal/domain/models"
+	"user-service/pkg/infra/logger"
+	"user-service/pkg/infra/metrics"
+	"user-service/pkg/requestid"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const principalKey = "principal"
+
+// authenticate resolves the caller from the Authorization header, if present, and stores it in the context.
+// Both user tokens ("Bearer <token>") and API keys ("ApiKey <key>") are accepted. In session mode, requests
+// without the header are authenticated with the session cookie. Requests without credentials pass through;
+// invalid credentials are rejected, while an invalid session cookie is just cleared.
+func (a *Adapter) authenticate(ctx *gin.Context) {
+	header := ctx.GetHeader("Authorization")
+	if header == "" {
+		a.authenticateSession(ctx)
+		ctx.Next()
+		return
+	}
+	scheme, credentials, _ := strings.Cut(header, " ")
+	credentials = strings.TrimSpace(credentials)
+
+	var (
+		principal models.Principal
+		err       error
+	)
+	switch {
+	case strings.EqualFold(scheme, "Bearer"):
+		principal, err = a.authSvc.Authenticate(ctx.Request.Context(), credentials)
+	case strings.EqualFold(scheme, "ApiKey"):
+		principal, err = a.apiKeySvc.Authenticate(ctx.Request.Context(), credentials)
+	default:
+		err = models.ErrUnauthorized
+	}
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		ctx.Abort()
+		return
+	}
+	a.setPrincipal(ctx, principal)
+	ctx.Next()
+}
+
+func (a *Adapter) authenticateSession(ctx *gin.Context) {
+	if a.opts.AuthMode != models.AuthModeSession {
+		return
+	}
+	sessionToken, err := ctx.Cookie(sessionCookie)
+	if err != nil || sessionToken == "" {
+		return
+	}
+	principal, err := a.sessionSvc.Authenticate(ctx.Request.Context(), sessionToken)
+	if err != nil {
+		a.clearSessionCookies(ctx)
+		return
+	}
+	a.setPrincipal(ctx, principal)
+	ctx.Set(sessionCookie, sessionToken)
+}
+
+// csrfProtect requires state-changing requests authenticated with a session cookie to carry the CSRF token
+// in the X-CSRF-Token header (double submit). Requests with credentials in the Authorization header can not
+// be forged by other sites and are not checked.
+func (a *Adapter) csrfProtect(ctx *gin.Context) {
+	switch ctx.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		ctx.Next()
+		return
+	}
+	sessionToken := ctx.GetString(sessionCookie)
+	if sessionToken == "" {
+		ctx.Next()
+		return
+	}
+	expected := a.sessionSvc.CSRFToken(sessionToken)
+	if subtle.ConstantTimeCompare([]byte(ctx.GetHeader(csrfHeader)), []byte(expected)) != 1 {
+		a.ErrorHandler(ctx, models.ErrInvalidCSRFToken)
+		ctx.Abort()
+		return
+	}
+	ctx.Next()
+}
+
+// resolveUserID looks up the user by the id path parameter and sets the username parameter to the
+// user's current username, so that the /user/id/:id routes are served by the /user/:username handlers.
+func (a *Adapter) resolveUserID(ctx *gin.Context) {
+	username, err := a.userSvc.GetUsernameByID(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		ctx.Abort()
+		return
+	}
+	ctx.Params = append(ctx.Params, gin.Param{Key: "username", Value: username})
+	ctx.Next()
+}
+
+// requireAuth rejects requests that were not made by a logged-in user.
+func (a *Adapter) requireAuth(ctx *gin.Context) {
+	if principal, ok := getPrincipal(ctx); !ok || principal.IsAPIKey() {
+		a.ErrorHandler(ctx, models.ErrUnauthorized)
+		ctx.Abort()
+		return
+	}
+	ctx.Next()
+}
+
+// requireAdmin allows only administrators and API keys with the admin scope. Administrators must have
+// passed two-factor authentication unless it is disabled in the options.
+func (a *Adapter) requireAdmin(ctx *gin.Context) {
+	principal, ok := getPrincipal(ctx)
+	switch {
+	case !ok:
+		a.ErrorHandler(ctx, models.ErrUnauthorized)
+	case !principal.IsAdmin():
+		a.ErrorHandler(ctx, models.ErrForbidden)
+	case !principal.IsAPIKey() && a.opts.AdminRequireMFA && !principal.HasMFA():
+		a.ErrorHandler(ctx, models.ErrMFARequired)
+	default:
+		ctx.Next()
+		return
+	}
+	ctx.Abort()
+}
+
+// requireScope rejects requests without credentials and limits the requests made with API keys to the keys
+// having the scope. Users are not limited by scopes; the services let them change their own account only.
+func (a *Adapter) requireScope(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, ok := getPrincipal(ctx)
+		switch {
+		case !ok:
+			a.ErrorHandler(ctx, models.ErrUnauthorized)
+		case !principal.HasScope(scope):
+			a.ErrorHandler(ctx, models.ErrForbidden)
+		default:
+			ctx.Next()
+			return
+		}
+		ctx.Abort()
+	}
+}
+
+// optionalScope limits the requests made with API keys to the keys having the scope, like requireScope,
+// but lets anonymous requests through. It guards the public endpoints: registration and avatar images.
+func (a *Adapter) optionalScope(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if principal, ok := getPrincipal(ctx); ok && !principal.HasScope(scope) {
+			a.ErrorHandler(ctx, models.ErrForbidden)
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// rateLimit limits the request rate of each client per route. Clients are identified by API key,
+// by user if authenticated, and by IP address otherwise. The limiter state is reported in the
+// RateLimit-* headers. If the limiter store fails, requests are let through.
+func (a *Adapter) rateLimit(ctx *gin.Context) {
+	if a.opts.RateLimiter == nil {
+		ctx.Next()
+		return
+	}
+	client := "ip:" + a.clientIP(ctx)
+	if principal, ok := getPrincipal(ctx); ok && principal.IsAPIKey() {
+		client = fmt.Sprintf("key:%d", principal.APIKeyID)
+	} else if ok {
+		client = "user:" + principal.Username
+	}
+
+	res, limited, err := a.opts.RateLimiter.Allow(ctx.Request.Context(), ctx.Request.Method+" "+ctx.FullPath(), client)
+	if err != nil {
+		logger.FromContext(ctx.Request.Context()).Warn("rate limiter failed", "desc", err.Error())
+		ctx.Next()
+		return
+	}
+	if !limited {
+		ctx.Next()
+		return
+	}
+	ctx.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+	ctx.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	ctx.Header("RateLimit-Reset", strconv.Itoa(int(res.Reset.Seconds())))
+	if !res.Allowed {
+		ctx.Header("Retry-After", strconv.Itoa(int(res.RetryAfter.Seconds())))
+		a.ErrorHandler(ctx, models.ErrTooManyRequests)
+		ctx.Abort()
+		return
+	}
+	ctx.Next()
+}
+
+// setPrincipal stores the authenticated caller in the gin and request contexts and adds it to the request
+// logger and the audit actor.
+func (a *Adapter) setPrincipal(ctx *gin.Context, principal models.Principal) {
+	ctx.Set(principalKey, principal)
+	user := principal.Username
+	if principal.IsAPIKey() {
+		user = fmt.Sprintf("key:%d", principal.APIKeyID)
+	}
+	c := ctx.Request.Context()
+	// the username is personal data and is masked in logs, so the caller is logged by its stable ID
+	log := logger.FromContext(c).With("user_id", principal.UserID)
+	if principal.IsAPIKey() {
+		log = logger.FromContext(c).With("api_key_id", principal.APIKeyID)
+	}
+	c = logger.WithContext(c, log)
+	c = models.WithAuditActor(c, models.AuditActor{Name: user, IP: a.clientIP(ctx)})
+	c = models.WithPrincipal(c, principal)
+	ctx.Request = ctx.Request.WithContext(c)
+}
+
+// clientIP returns the address of the client. X-Forwarded-For is used only if the request came from one of the
+// trusted proxies, otherwise any client could set the address used for rate limiting and audit.
+func (a *Adapter) clientIP(ctx *gin.Context) string {
+	if len(a.opts.TrustedProxies) == 0 {
+		return ctx.RemoteIP()
+	}
+	return ctx.ClientIP()
+}
+
+func getPrincipal(ctx *gin.Context) (models.Principal, bool) {
+	v, ok := ctx.Get(principalKey)
+	if !ok {
+		return models.Principal{}, false
+	}
+	principal, ok := v.(models.Principal)
+	return principal, ok
+}
+
+// SetCORSOrigins replaces the origins allowed to make cross-site requests. "*" allows any origin,
+// but without credentials.
+func (a *Adapter) SetCORSOrigins(origins []string) {
+	if len(origins) == 0 {
+		// no CORS headers, so browsers reject cross-site requests
+		handler := gin.HandlerFunc(func(ctx *gin.Context) { ctx.Next() })
+		a.cors.Store(&handler)
+		return
+	}
+	config := cors.DefaultConfig()
+	config.AllowHeaders = append(config.AllowHeaders, "Authorization", csrfHeader, requestid.Header)
+	config.ExposeHeaders = append(config.ExposeHeaders, requestid.Header)
+	if slices.Contains(origins, "*") {
+		config.AllowAllOrigins = true
+	} else {
+		// cookies are sent cross-site only to the listed origins
+		config.AllowOrigins = origins
+		config.AllowCredentials = true
+	}
+	handler := cors.New(config)
+	a.cors.Store(&handler)
+}
+
+// handleCORS applies the current CORS policy.
+func (a *Adapter) handleCORS(ctx *gin.Context) {
+	(*a.cors.Load())(ctx)
+}
+
+// redactPath masks the username in /user/:username paths.
+func redactPath(path string) string {
+	parts := strings.SplitN(path, "/", 4)
+	if len(parts) >= 3 && parts[1] == "user" && parts[2] != "" {
+		parts[2] = logger.Redacted
+	}
+	return strings.Join(parts, "/")
+}
+
+// requestID takes the request ID from the X-Request-ID header, or generates one if it is missing or
+// invalid, stores it in the request context and echoes it in the response. The client IP address is
+// stored as the audit actor until the caller is authenticated.
+func (a *Adapter) requestID(ctx *gin.Context) {
+	id := ctx.GetHeader(requestid.Header)
+	if !requestid.Valid(id) {
+		id = requestid.New()
+	}
+	ctx.Header(requestid.Header, id)
+	trace.SpanFromContext(ctx.Request.Context()).SetAttributes(attribute.String("http.request_id", id))
+	c := requestid.WithContext(ctx.Request.Context(), id)
+	// anonymous requests are audited by IP address
+	c = models.WithAuditActor(c, models.AuditActor{IP: a.clientIP(ctx)})
+	ctx.Request = ctx.Request.WithContext(c)
+	ctx.Next()
+}
+
+// logRequest stores the logger carrying the request ID and route in the request context, so that
+// everything logged while serving the request can be correlated, and writes the access log entry.
+// The user is added on authentication.
+func (a *Adapter) logRequest(ctx *gin.Context) {
+	start := time.Now()
+	log := logger.Get().With(
+		"request_id", requestid.FromContext(ctx.Request.Context()),
+		"route", ctx.Request.Method+" "+ctx.FullPath(),
+	)
+	ctx.Request = ctx.Request.WithContext(logger.WithContext(ctx.Request.Context(), log))
+
+	ctx.Next()
+
+	status := ctx.Writer.Status()
+	level := slog.LevelInfo
+	switch {
+	case status >= http.StatusInternalServerError:
+		level = slog.LevelError
+	case status >= http.StatusBadRequest:
+		level = slog.LevelWarn
+	}
+	logger.FromContext(ctx.Request.Context()).LogAttrs(ctx.Request.Context(), level, "request handled",
+		slog.Int("status", status),
+		slog.String("method", ctx.Request.Method),
+		slog.String("path", redactPath(ctx.Request.URL.Path)),
+		slog.String("ip", a.clientIP(ctx)),
+		slog.Duration("latency", time.Since(start)),
+		slog.String("user-agent", ctx.Request.UserAgent()),
+	)
+}
+
+// measure records the request count and latency by method, route pattern and status code.
+func (a *Adapter) measure(ctx *gin.Context) {
+	start := time.Now()
+	metrics.HTTPRequestsInFlight.Inc()
+	defer metrics.HTTPRequestsInFlight.Dec()
+
+	ctx.Next()
+
+	route := ctx.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	status := strconv.Itoa(ctx.Writer.Status())
+	metrics.HTTPRequests.WithLabelValues(ctx.Request.Method, route, status).Inc()
+	metrics.HTTPRequestDuration.WithLabelValues(ctx.Request.Method, route, status).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,217 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"user-service/internal/domain/models"
+	"user-service/internal/ports"
+	"user-service/pkg/infra/logger"
+	"user-service/pkg/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSetPrincipalLogsStableID(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal models.Principal
+		key       string
+		want      any
+	}{
+		{
+			name:      "user",
+			principal: models.Principal{UserID: testUserID, Username: testUsername, Role: models.RoleUser},
+			key:       "user_id", want: testUserID,
+		},
+		{
+			name:      "api key",
+			principal: models.Principal{Username: "api-key:crm", APIKeyID: 7, Scopes: []string{models.ScopeUsersRead}},
+			key:       "api_key_id", want: float64(7),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := slog.New(logger.NewRedactHandler(slog.NewJSONHandler(&buf, nil), logger.RedactOptions{Keys: logger.DefaultRedactKeys}))
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/user/"+testUsername, nil)
+			ctx.Request = ctx.Request.WithContext(logger.WithContext(ctx.Request.Context(), log))
+
+			(&Adapter{}).setPrincipal(ctx, tt.principal)
+			logger.FromContext(ctx.Request.Context()).Info("msg")
+
+			var rec map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+				t.Fatal(err)
+			}
+			if rec[tt.key] != tt.want {
+				t.Errorf("%s = %v, want %v: %s", tt.key, rec[tt.key], tt.want, buf.String())
+			}
+			if strings.Contains(buf.String(), testUsername) || strings.Contains(buf.String(), logger.Redacted) {
+				t.Errorf("the caller is logged by username: %s", buf.String())
+			}
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	long := strings.Repeat("a", 64)
+	tests := []struct {
+		name     string
+		incoming string // empty if the header is not sent
+		keep     bool   // the incoming ID is used
+	}{
+		{name: "propagated", incoming: "5f0c2a9e-1b7d-4c3e-8a6f-2d9b0e4c7a1f", keep: true},
+		{name: "other format", incoming: "gw:1700000000.42_abc", keep: true},
+		{name: "longest", incoming: long, keep: true},
+		{name: "missing"},
+		{name: "oversized", incoming: long + "a"},
+		{name: "invalid characters", incoming: "id with spaces"},
+		{name: "sql comment", incoming: "id*/;--"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inContext string
+			r := gin.New()
+			r.Use((&Adapter{}).requestID)
+			r.GET("/", func(ctx *gin.Context) {
+				inContext = requestid.FromContext(ctx.Request.Context())
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(requestid.Header, tt.incoming)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			echoed := w.Header().Get(requestid.Header)
+			if echoed != inContext {
+				t.Errorf("response header %s = %q, request context %q, want the same", requestid.Header, echoed, inContext)
+			}
+			if tt.keep {
+				if inContext != tt.incoming {
+					t.Errorf("request ID = %q, want the incoming %q", inContext, tt.incoming)
+				}
+				return
+			}
+			if inContext == tt.incoming || !requestid.Valid(inContext) {
+				t.Errorf("request ID = %q, want a new valid one", inContext)
+			}
+		})
+	}
+
+	// generated IDs differ between requests
+	r := gin.New()
+	r.Use((&Adapter{}).requestID)
+	r.GET("/", func(*gin.Context) {})
+	ids := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		ids[w.Header().Get(requestid.Header)] = true
+	}
+	if len(ids) != 3 {
+		t.Errorf("generated request IDs %v, want 3 different ones", ids)
+	}
+}
+
+// authService authenticates the access tokens it knows.
+type authService struct {
+	ports.AuthService
+	principals map[string]models.Principal
+}
+
+func (s authService) Authenticate(_ context.Context, token string) (models.Principal, error) {
+	principal, ok := s.principals[token]
+	if !ok {
+		return models.Principal{}, models.ErrUnauthorized
+	}
+	return principal, nil
+}
+
+// apiKeyService authenticates the API keys it knows.
+type apiKeyService struct {
+	ports.APIKeyService
+	principals map[string]models.Principal
+}
+
+func (s apiKeyService) Authenticate(_ context.Context, key string) (models.Principal, error) {
+	principal, ok := s.principals[key]
+	if !ok {
+		return models.Principal{}, models.ErrUnauthorized
+	}
+	return principal, nil
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	apiKey := func(id int64, scopes ...string) models.Principal {
+		return models.Principal{Username: "api-key:crm", APIKeyID: id, Scopes: scopes}
+	}
+	a := &Adapter{
+		authSvc: authService{principals: map[string]models.Principal{
+			"user-token": {UserID: testUserID, Username: testUsername, Role: models.RoleUser},
+		}},
+		apiKeySvc: apiKeyService{principals: map[string]models.Principal{
+			"usk_read":  apiKey(1, models.ScopeUsersRead),
+			"usk_write": apiKey(2, models.ScopeUsersWrite),
+			"usk_admin": apiKey(3, models.ScopeAdmin),
+		}},
+	}
+	r := gin.New()
+	r.Use(a.authenticate)
+	ok := func(ctx *gin.Context) { ctx.Status(http.StatusNoContent) }
+	r.GET("/user/:username", a.requireScope(models.ScopeUsersRead), ok)
+	r.PUT("/user/:username", a.requireScope(models.ScopeUsersWrite), ok)
+	r.POST("/user", a.optionalScope(models.ScopeUsersWrite), ok)
+	r.GET("/admin/api-keys", a.requireAdmin, ok)
+	r.GET("/auth/me", a.requireAuth, ok)
+
+	tests := []struct {
+		auth        string // Authorization header
+		read, write int    // GET and PUT /user/:username
+		register    int    // POST /user
+		admin       int    // GET /admin/api-keys
+		me          int    // GET /auth/me
+	}{
+		{auth: "", read: 401, write: 401, register: 204, admin: 401, me: 401},
+		{auth: "ApiKey usk_read", read: 204, write: 403, register: 403, admin: 403, me: 401},
+		{auth: "ApiKey usk_write", read: 403, write: 204, register: 204, admin: 403, me: 401},
+		{auth: "ApiKey usk_admin", read: 204, write: 204, register: 204, admin: 204, me: 401},
+		{auth: "apikey usk_read", read: 204, write: 403, register: 403, admin: 403, me: 401},
+		{auth: "ApiKey usk_unknown", read: 401, write: 401, register: 401, admin: 401, me: 401},
+		{auth: "Bearer usk_admin", read: 401, write: 401, register: 401, admin: 401, me: 401},
+		{auth: "Basic usk_admin", read: 401, write: 401, register: 401, admin: 401, me: 401},
+		// users are not limited by scopes, the services check the account they change
+		{auth: "Bearer user-token", read: 204, write: 204, register: 204, admin: 403, me: 204},
+	}
+	for _, tt := range tests {
+		t.Run(tt.auth, func(t *testing.T) {
+			for _, route := range []struct {
+				method, path string
+				want         int
+			}{
+				{http.MethodGet, "/user/" + testUsername, tt.read},
+				{http.MethodPut, "/user/" + testUsername, tt.write},
+				{http.MethodPost, "/user", tt.register},
+				{http.MethodGet, "/admin/api-keys", tt.admin},
+				{http.MethodGet, "/auth/me", tt.me},
+			} {
+				req := httptest.NewRequest(route.method, route.path, nil)
+				if tt.auth != "" {
+					req.Header.Set("Authorization", tt.auth)
+				}
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				if w.Code != route.want {
+					t.Errorf("%s %s = %d, want %d", route.method, route.path, w.Code, route.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package http
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+	"user-service/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The login form carries a token that must match the HttpOnly cookie set with the form (double submit),
+// so that other sites cannot log users in with the attacker's account (login CSRF).
+const (
+	loginCSRFCookie = "oidc_login_csrf"
+	loginCSRFField  = "csrf_token"
+	loginCSRFTTL    = time.Hour
+)
+
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Sign in to {{.Client}}</title></head>
+<body>
+<h1>Sign in to {{.Client}}</h1>
+{{if .Error}}<p style="color: red">{{.Error}}</p>{{end}}
+<form method="post" action="/oauth2/authorize">
+  <p><label>Username <input name="username" autocomplete="username" required></label></p>
+  <p><label>Password <input name="password" type="password" autocomplete="current-password" required></label></p>
+  <p><label>One-time code (if 2FA is enabled) <input name="otp" autocomplete="one-time-code"></label></p>
+  <input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+  {{range $name, $value := .Params}}<input type="hidden" name="{{$name}}" value="{{$value}}">
+  {{end}}<p><button type="submit">Sign in</button></p>
+</form>
+</body>
+</html>
+`))
+
+// @ID oidcDiscovery
+// @tags oidc
+// @Summary OpenID Provider configuration
+// @Description Returns the OpenID Connect discovery document.
+// @Success 200 {object} models.DiscoveryDocument
+// @Router /.well-known/openid-configuration [get]
+func (a *Adapter) oidcDiscovery(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, a.oidcSvc.Discovery())
+}
+
+// @ID oidcJWKS
+// @tags oidc
+// @Summary Token signing keys
+// @Description Returns the public keys used to sign ID tokens, in JWK Set format.
+// @Success 200 {object} token.JWKS
+// @Router /oauth2/jwks [get]
+func (a *Adapter) oidcJWKS(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, a.oidcSvc.JWKS())
+}
+
+// @ID oidcAuthorize
+// @tags oidc
+// @Summary Authorization endpoint
+// @Description Starts the authorization code flow. If the request is made by a logged-in user, redirects back to the client with a code, otherwise shows the login form. PKCE (S256) is required for public clients.
+// @Produce html
+// @Param response_type query string true "must be 'code'"
+// @Param client_id query string true "client id"
+// @Param redirect_uri query string true "one of the registered redirect URIs"
+// @Param scope query string true "space separated scopes, must include 'openid'"
+// @Param state query string false "opaque value returned to the client"
+// @Param nonce query string false "value included in the ID token"
+// @Param code_challenge query string false "PKCE code challenge"
+// @Param code_challenge_method query string false "must be 'S256'"
+// @Success 200 {string} string "Login form."
+// @Success 302 {string} string "Redirect to the client."
+// @Failure 400 {object} models.ErrorResponse "Invalid redirect_uri."
+// @Failure 404 {object} models.ErrorResponse "Client not found."
+// @Router /oauth2/authorize [get]
+func (a *Adapter) oidcAuthorize(ctx *gin.Context) {
+	var req models.AuthorizeRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+	client, err := a.oidcSvc.ValidateAuthorizeRequest(ctx.Request.Context(), req)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	if principal, ok := getPrincipal(ctx); ok && !principal.IsAPIKey() {
+		a.redirectWithCode(ctx, req, principal)
+		return
+	}
+	a.renderLoginPage(ctx, http.StatusOK, client, req, "")
+}
+
+// @ID oidcAuthorizeLogin
+// @tags oidc
+// @Summary Submit the login form
+// @Description Checks the user's credentials (and one-time code if 2FA is enabled) and redirects back to the client with an authorization code.
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Param username formData string true "username"
+// @Param password formData string true "password"
+// @Param otp formData string false "TOTP or recovery code"
+// @Param csrf_token formData string true "token of the login form"
+// @Success 302 {string} string "Redirect to the client."
+// @Failure 401 {string} string "Login form with an error."
+// @Failure 403 {string} string "Login form with an error: the form token is missing or does not match the cookie."
+// @Failure 400 {object} models.ErrorResponse "Invalid redirect_uri."
+// @Failure 404 {object} models.ErrorResponse "Client not found."
+// @Router /oauth2/authorize [post]
+func (a *Adapter) oidcAuthorizeLogin(ctx *gin.Context) {
+	var req models.AuthorizeRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+	client, err := a.oidcSvc.ValidateAuthorizeRequest(ctx.Request.Context(), req)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	cookie, err := ctx.Cookie(loginCSRFCookie)
+	if err != nil || cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(ctx.PostForm(loginCSRFField))) != 1 {
+		a.renderLoginPage(ctx, http.StatusForbidden, client, req, "The login form has expired, please sign in again.")
+		return
+	}
+	principal, err := a.authSvc.AuthenticatePassword(ctx.Request.Context(), ctx.PostForm("username"), ctx.PostForm("password"), ctx.PostForm("otp"))
+	if err != nil {
+		a.renderLoginPage(ctx, http.StatusUnauthorized, client, req, err.Error())
+		return
+	}
+	if a.opts.AuthMode == models.AuthModeSession {
+		// keep the user logged in, so that other clients skip the login form
+		if _, _, err = a.startSession(ctx, principal); err != nil {
+			a.ErrorHandler(ctx, err)
+			return
+		}
+	}
+	a.redirectWithCode(ctx, req, principal)
+}
+
+// @ID oidcToken
+// @tags oidc
+// @Summary Token endpoint
+// @Description Exchanges an authorization code for an ID token and an access token. Confidential clients authenticate with HTTP basic authentication or the client_secret parameter.
+// @Accept x-www-form-urlencoded
+// @Param grant_type formData string true "must be 'authorization_code'"
+// @Param code formData string true "authorization code"
+// @Param redirect_uri formData string true "redirect URI used in the authorization request"
+// @Param client_id formData string false "client id, if basic authentication is not used"
+// @Param client_secret formData string false "client secret, if basic authentication is not used"
+// @Param code_verifier formData string false "PKCE code verifier"
+// @Success 200 {object} models.TokenResponse
+// @Failure 400 {object} models.OAuthError
+// @Failure 401 {object} models.OAuthError
+// @Router /oauth2/token [post]
+func (a *Adapter) oidcToken(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+	var req models.TokenRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.NewOAuthError("invalid_request", "malformed request"))
+		return
+	}
+	if id, secret, ok := ctx.Request.BasicAuth(); ok {
+		req.ClientID, req.ClientSecret = id, secret
+	}
+	resp, err := a.oidcSvc.Exchange(ctx.Request.Context(), req)
+	if err != nil {
+		var oauthErr *models.OAuthError
+		switch {
+		case errors.As(err, &oauthErr) && oauthErr.Code == "invalid_client":
+			ctx.JSON(http.StatusUnauthorized, oauthErr)
+		case errors.As(err, &oauthErr):
+			ctx.JSON(http.StatusBadRequest, oauthErr)
+		default:
+			a.ErrorHandler(ctx, err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @ID oidcUserInfo
+// @tags oidc
+// @Summary UserInfo endpoint
+// @Description Returns the claims about the user allowed by the scopes of the access token issued by the token endpoint.
+// @Param Authorization header string true "Bearer <access_token>"
+// @Success 200 {object} models.UserInfo
+// @Failure 401 {object} models.ErrorResponse "Invalid or expired access token."
+// @Failure 404 {object} models.ErrorResponse "User not found."
+// @Router /oauth2/userinfo [get]
+func (a *Adapter) oidcUserInfo(ctx *gin.Context) {
+	scheme, accessToken, _ := strings.Cut(ctx.GetHeader("Authorization"), " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		ctx.Header("WWW-Authenticate", "Bearer")
+		a.ErrorHandler(ctx, models.ErrUnauthorized)
+		return
+	}
+	info, err := a.oidcSvc.UserInfo(ctx.Request.Context(), strings.TrimSpace(accessToken))
+	if err != nil {
+		ctx.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, info)
+}
+
+// @ID createOAuthClient
+// @tags admin
+// @Summary Register OAuth client
+// @Description Registers an application that logs users in through the service. Confidential clients receive a client secret, which is returned only in this response.
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept json
+// @Param request body models.CreateOAuthClientRequest true "client name, redirect URIs and type"
+// @Success 200 {object} models.CreateOAuthClientResponse "Client registered."
+// @Failure 400 {object} models.ErrorResponse "Missing required parameters / invalid redirect URI."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Access denied / two-factor authentication required."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /admin/oauth/clients [post]
+func (a *Adapter) createOAuthClient(ctx *gin.Context) {
+	var req models.CreateOAuthClientRequest
+	err := ctx.BindJSON(&req)
+	if err != nil {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+	resp, err := a.oidcSvc.RegisterClient(ctx.Request.Context(), req)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @ID listOAuthClients
+// @tags admin
+// @Summary List OAuth clients
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Success 200 {array} models.OAuthClient "Registered clients."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Access denied / two-factor authentication required."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /admin/oauth/clients [get]
+func (a *Adapter) listOAuthClients(ctx *gin.Context) {
+	clients, err := a.oidcSvc.ListClients(ctx.Request.Context())
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, clients)
+}
+
+// @ID deleteOAuthClient
+// @tags admin
+// @Summary Delete OAuth client
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param client_id path string true "id of the client to delete"
+// @Success 200 {object} models.SuccessResponse "Client deleted."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Access denied / two-factor authentication required."
+// @Failure 404 {object} models.ErrorResponse "Client not found."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /admin/oauth/clients/{client_id} [delete]
+func (a *Adapter) deleteOAuthClient(ctx *gin.Context) {
+	clientID := ctx.Param("client_id")
+	err := a.oidcSvc.DeleteClient(ctx.Request.Context(), clientID)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Success: fmt.Sprintf("oauth client '%s' deleted", clientID)},
+	)
+}
+
+func (a *Adapter) redirectWithCode(ctx *gin.Context, req models.AuthorizeRequest, principal models.Principal) {
+	location, err := a.oidcSvc.Authorize(ctx.Request.Context(), req, principal)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.Redirect(http.StatusFound, location)
+}
+
+func (a *Adapter) renderLoginPage(ctx *gin.Context, status int, client models.OAuthClient, req models.AuthorizeRequest, errMsg string) {
+	params := map[string]string{
+		"response_type":         req.ResponseType,
+		"client_id":             req.ClientID,
+		"redirect_uri":          req.RedirectURI,
+		"scope":                 req.Scope,
+		"state":                 req.State,
+		"nonce":                 req.Nonce,
+		"code_challenge":        req.CodeChallenge,
+		"code_challenge_method": req.CodeChallengeMethod,
+	}
+	// the token is kept while the cookie lives, so that forms opened in several tabs stay valid
+	csrfToken, err := ctx.Cookie(loginCSRFCookie)
+	if err != nil || csrfToken == "" {
+		buf := make([]byte, 32)
+		if _, err = rand.Read(buf); err != nil {
+			a.ErrorHandler(ctx, err)
+			return
+		}
+		csrfToken = base64.RawURLEncoding.EncodeToString(buf)
+	}
+	var page bytes.Buffer
+	err = loginPage.Execute(&page, map[string]any{"Client": client.Name, "Error": errMsg, "Params": params, "CSRFToken": csrfToken})
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(loginCSRFCookie, csrfToken, int(loginCSRFTTL.Seconds()), "/oauth2/authorize", "", a.opts.SessionCookieSecure, true)
+	ctx.Header("Cache-Control", "no-store")
+	// the form must not be framed by other sites (clickjacking)
+	ctx.Header("X-Frame-Options", "DENY")
+	ctx.Header("Content-Security-Policy", "frame-ancestors 'none'")
+	ctx.Data(status, "text/html; charset=utf-8", page.Bytes())
+}
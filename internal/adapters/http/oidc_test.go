@@ -0,0 +1,475 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"user-service/internal/domain/models"
+	"user-service/internal/domain/usecases"
+	"user-service/internal/ports"
+	"user-service/pkg/token"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	testUsername = "IvanIvanov2000"
+	testPassword = "qwerty1234"
+	testUserID   = "018f3b6e-6c1a-7d2e-9a4b-3c5d7e9f1a2b"
+)
+
+// oidcStorage keeps OAuth clients and authorization codes in memory.
+type oidcStorage struct {
+	ports.OIDCStorage
+	mu      sync.Mutex
+	clients map[string]models.OAuthClient
+	codes   map[string]models.AuthorizationCode
+}
+
+func (s *oidcStorage) GetOAuthClient(_ context.Context, clientID string) (models.OAuthClient, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	client, ok := s.clients[clientID]
+	if !ok {
+		return models.OAuthClient{}, errors.New("no rows")
+	}
+	return client, nil
+}
+
+func (s *oidcStorage) SaveAuthorizationCode(_ context.Context, code models.AuthorizationCode) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.codes[code.CodeHash] = code
+	return nil
+}
+
+func (s *oidcStorage) ConsumeAuthorizationCode(_ context.Context, codeHash string) (models.AuthorizationCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code, ok := s.codes[codeHash]
+	if !ok {
+		return models.AuthorizationCode{}, errors.New("no rows")
+	}
+	delete(s.codes, codeHash)
+	return code, nil
+}
+
+// userStorage holds a single user, for both the OIDC and the auth services.
+type userStorage struct {
+	ports.UserStorage
+	ports.AuthStorage
+	mu   sync.Mutex
+	user models.GetUserResponse
+	cred models.Credentials
+}
+
+func (s *userStorage) setStatus(status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user.Status = status
+	s.cred.Status = status
+}
+
+func (s *userStorage) rename(username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user.Username = username
+	s.cred.Username = username
+}
+
+func (s *userStorage) GetUser(_ context.Context, username string) (models.GetUserResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if username != s.user.Username {
+		return models.GetUserResponse{}, errors.New("no rows")
+	}
+	return s.user, nil
+}
+
+func (s *userStorage) GetUsernameByID(_ context.Context, id string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id != s.user.ID {
+		return "", errors.New("no rows")
+	}
+	return s.user.Username, nil
+}
+
+func (s *userStorage) GetCredentials(_ context.Context, username string) (models.Credentials, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if username != s.cred.Username {
+		return models.Credentials{}, errors.New("no rows")
+	}
+	return s.cred, nil
+}
+
+func (s *userStorage) GetCredentialsByID(_ context.Context, id string) (models.Credentials, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id != s.cred.UserID {
+		return models.Credentials{}, errors.New("no rows")
+	}
+	return s.cred, nil
+}
+
+func (s *userStorage) RecordLogin(context.Context, string) error {
+	return nil
+}
+
+// oidcProvider is the service under test, serving the OIDC endpoints at the URL of the returned server.
+type oidcProvider struct {
+	server *httptest.Server
+	users  *userStorage
+	codes  *oidcStorage
+}
+
+func newOIDCProvider(t *testing.T, redirectURI string) *oidcProvider {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &userStorage{
+		user: models.GetUserResponse{
+			ID: testUserID, Username: testUsername, FirstName: "Ivan", LastName: "Ivanov",
+			Email: "iivanov@gmail.com", Phone: "+79999999999", Status: models.UserStatusActive,
+		},
+		cred: models.Credentials{
+			UserID: testUserID, Username: testUsername, PasswordHash: string(hash), Role: models.RoleUser, Status: models.UserStatusActive,
+		},
+	}
+	codes := &oidcStorage{
+		clients: map[string]models.OAuthClient{
+			"local": {ClientID: "local", Name: "Local client", RedirectURIs: []string{redirectURI}, Public: true},
+		},
+		codes: map[string]models.AuthorizationCode{},
+	}
+	key, err := token.LoadRSAKey("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the issuer is the URL of the server, known once it is started
+	router := gin.New()
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	a := &Adapter{
+		opts:    AdapterOptions{AuthMode: models.AuthModeToken},
+		authSvc: usecases.NewAuthSvc(users, usecases.AuthOptions{Secret: "secret", TokenTTL: time.Hour, MFATokenTTL: time.Minute}),
+		oidcSvc: usecases.NewOIDCSvc(codes, users, token.NewRSASigner(key), usecases.OIDCOptions{
+			Issuer: server.URL, CodeTTL: time.Minute, TokenTTL: time.Hour,
+		}),
+	}
+	a.SetCORSOrigins(nil)
+	if err = initRouter(a, router); err != nil {
+		t.Fatal(err)
+	}
+	return &oidcProvider{server: server, users: users, codes: codes}
+}
+
+// relyingParty is a minimal local client running the authorization code flow with PKCE.
+type relyingParty struct {
+	server    *httptest.Server
+	discovery models.DiscoveryDocument
+	state     string
+	nonce     string
+	verifier  string
+	callbacks chan url.Values
+}
+
+func newRelyingParty(t *testing.T) *relyingParty {
+	t.Helper()
+	rp := &relyingParty{state: random(t), nonce: random(t), verifier: random(t), callbacks: make(chan url.Values, 1)}
+	rp.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rp.callbacks <- r.URL.Query()
+		io.WriteString(w, "logged in")
+	}))
+	t.Cleanup(rp.server.Close)
+	return rp
+}
+
+func (rp *relyingParty) redirectURI() string {
+	return rp.server.URL + "/callback"
+}
+
+func (rp *relyingParty) authorizationURL() string {
+	challenge := sha256.Sum256([]byte(rp.verifier))
+	return rp.discovery.AuthorizationEndpoint + "?" + url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"local"},
+		"redirect_uri":          {rp.redirectURI()},
+		"scope":                 {"openid profile email"},
+		"state":                 {rp.state},
+		"nonce":                 {rp.nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}.Encode()
+}
+
+// login signs in to the provider in a new browser and returns the authorization code sent to the client.
+func (rp *relyingParty) login(t *testing.T) string {
+	t.Helper()
+	client := browser(t)
+	form := openLoginForm(t, client, rp.authorizationURL())
+	if form.Get(loginCSRFField) == "" {
+		t.Fatal("login form has no csrf token")
+	}
+	form.Set("username", testUsername)
+	form.Set("password", testPassword)
+	resp, err := client.PostForm(rp.discovery.AuthorizationEndpoint, form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Request.URL.Host != strings.TrimPrefix(rp.server.URL, "http://") {
+		t.Fatalf("login did not redirect to the client: %s %s", resp.Status, resp.Request.URL)
+	}
+	callback := <-rp.callbacks
+	if callback.Get("state") != rp.state {
+		t.Fatalf("state = %q, want %q", callback.Get("state"), rp.state)
+	}
+	return callback.Get("code")
+}
+
+func (rp *relyingParty) exchange(t *testing.T, code string) (*http.Response, models.TokenResponse) {
+	t.Helper()
+	resp, err := http.PostForm(rp.discovery.TokenEndpoint, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {rp.redirectURI()},
+		"client_id":     {"local"},
+		"code_verifier": {rp.verifier},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var tokens models.TokenResponse
+	if resp.StatusCode == http.StatusOK {
+		if err = json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp, tokens
+}
+
+// browser follows redirects and keeps cookies, like the user's browser.
+func browser(t *testing.T) *http.Client {
+	t.Helper()
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Client{Jar: jar}
+}
+
+var hiddenInput = regexp.MustCompile(`<input type="hidden" name="([^"]+)" value="([^"]*)">`)
+
+// openLoginForm opens the authorization URL and returns the hidden fields of the login form.
+func openLoginForm(t *testing.T, client *http.Client, authURL string) url.Values {
+	t.Helper()
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET authorize: %s: %s", resp.Status, body)
+	}
+	if got := resp.Header.Get("X-Frame-Options"); got != "DENY" {
+		t.Errorf("X-Frame-Options = %q, want DENY", got)
+	}
+	if got := resp.Header.Get("Content-Security-Policy"); got != "frame-ancestors 'none'" {
+		t.Errorf("Content-Security-Policy = %q, want frame-ancestors 'none'", got)
+	}
+	form := url.Values{}
+	for _, m := range hiddenInput.FindAllStringSubmatch(string(body), -1) {
+		form.Set(m[1], unescapeHTML(m[2]))
+	}
+	return form
+}
+
+func TestOIDCAuthorizationCodeFlow(t *testing.T) {
+	rp := newRelyingParty(t)
+	provider := newOIDCProvider(t, rp.redirectURI())
+	if err := getJSON(provider.server.URL+"/.well-known/openid-configuration", "", &rp.discovery); err != nil {
+		t.Fatal(err)
+	}
+	if rp.discovery.Issuer != provider.server.URL {
+		t.Fatalf("issuer = %q, want %q", rp.discovery.Issuer, provider.server.URL)
+	}
+
+	code := rp.login(t)
+	resp, tokens := rp.exchange(t, code)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("token request: %s", resp.Status)
+	}
+	var jwks token.JWKS
+	if err := getJSON(rp.discovery.JWKSURI, "", &jwks); err != nil {
+		t.Fatal(err)
+	}
+	pub, err := jwks.Keys[0].PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var claims models.IDTokenClaims
+	if err = token.VerifyRS256(pub, tokens.IDToken, &claims); err != nil {
+		t.Fatalf("id token verification failed: %s", err)
+	}
+	switch {
+	case claims.Issuer != rp.discovery.Issuer:
+		t.Errorf("id token issuer = %q", claims.Issuer)
+	case claims.Audience != "local":
+		t.Errorf("id token audience = %q", claims.Audience)
+	case claims.Nonce != rp.nonce:
+		t.Errorf("id token nonce = %q", claims.Nonce)
+	case claims.Subject != testUserID:
+		t.Errorf("id token subject = %q, want the user ID", claims.Subject)
+	case claims.PreferredUsername != testUsername:
+		t.Errorf("id token preferred_username = %q", claims.PreferredUsername)
+	}
+
+	var info models.UserInfo
+	if err = getJSON(rp.discovery.UserInfoEndpoint, tokens.AccessToken, &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Subject != claims.Subject || info.Email != "iivanov@gmail.com" || info.PhoneNumber != "" {
+		t.Errorf("userinfo = %+v, want the claims allowed by the scopes of the id token subject %q", info, claims.Subject)
+	}
+
+	// the subject stays the same after the user is renamed
+	provider.users.rename("IvanIvanov2001")
+	if err = getJSON(rp.discovery.UserInfoEndpoint, tokens.AccessToken, &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Subject != claims.Subject || info.PreferredUsername != "IvanIvanov2001" {
+		t.Errorf("userinfo after rename = %+v, want subject %q and the new username", info, claims.Subject)
+	}
+
+	// codes are single-use
+	if resp, _ = rp.exchange(t, code); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("replayed code: %s, want 400", resp.Status)
+	}
+}
+
+func TestOIDCRejectsInactiveUsers(t *testing.T) {
+	rp := newRelyingParty(t)
+	provider := newOIDCProvider(t, rp.redirectURI())
+	if err := getJSON(provider.server.URL+"/.well-known/openid-configuration", "", &rp.discovery); err != nil {
+		t.Fatal(err)
+	}
+
+	// suspended after logging in to the provider, before the client redeems the code
+	code := rp.login(t)
+	provider.users.setStatus(models.UserStatusSuspended)
+	if resp, _ := rp.exchange(t, code); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("token request for a suspended user: %s, want 400", resp.Status)
+	}
+
+	// suspended after the client got the tokens
+	provider.users.setStatus(models.UserStatusActive)
+	resp, tokens := rp.exchange(t, rp.login(t))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("token request: %s", resp.Status)
+	}
+	provider.users.setStatus(models.UserStatusSuspended)
+	var info models.UserInfo
+	if err := getJSON(rp.discovery.UserInfoEndpoint, tokens.AccessToken, &info); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("userinfo of a suspended user: %v, want 401", err)
+	}
+}
+
+func TestOIDCLoginFormRequiresCSRFToken(t *testing.T) {
+	rp := newRelyingParty(t)
+	provider := newOIDCProvider(t, rp.redirectURI())
+	if err := getJSON(provider.server.URL+"/.well-known/openid-configuration", "", &rp.discovery); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		cookie bool // the browser opened the form, so it has the cookie
+		token  string
+	}{
+		{name: "forged form without the cookie", cookie: false, token: "forged"},
+		{name: "missing token", cookie: true, token: ""},
+		{name: "wrong token", cookie: true, token: "forged"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := browser(t)
+			form := url.Values{}
+			if tt.cookie {
+				form = openLoginForm(t, client, rp.authorizationURL())
+			} else {
+				u, _ := url.Parse(rp.authorizationURL())
+				form = u.Query()
+			}
+			form.Set(loginCSRFField, tt.token)
+			form.Set("username", testUsername)
+			form.Set("password", testPassword)
+			resp, err := client.PostForm(rp.discovery.AuthorizationEndpoint, form)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusForbidden {
+				t.Errorf("status = %s, want 403", resp.Status)
+			}
+			select {
+			case <-rp.callbacks:
+				t.Error("the client received a code")
+			default:
+			}
+		})
+	}
+}
+
+func getJSON(endpoint, accessToken string, dst any) error {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("GET " + endpoint + ": " + resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
+
+func unescapeHTML(s string) string {
+	return strings.NewReplacer("&amp;", "&", "&#43;", "+", "&#34;", `"`, "&#39;", "'", "&lt;", "<", "&gt;", ">").Replace(s)
+}
+
+func random(t *testing.T) string {
+	t.Helper()
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"user-service/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @ID getPreferences
+// @tags user
+// @Summary User preferences
+// @Description Returns the user's preferences: the defaults with the values the user has set.
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param username path string true "username of the user"
+// @Success 200 {object} models.Preferences "User preferences."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Users may access their own preferences only."
+// @Failure 404 {object} models.ErrorResponse "User with given username not found."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /user/{username}/preferences [get]
+func (a *Adapter) getPreferences(ctx *gin.Context) {
+	username, err := a.getUsernameFromPath(ctx)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	prefs, err := a.preferencesSvc.GetPreferences(ctx.Request.Context(), username)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, prefs)
+}
+
+// @ID setPreferences
+// @tags user
+// @Summary Replace user preferences
+// @Description Replaces the preferences the user has set; the ones not given revert to the defaults. Returns the resulting preferences.
+// @Accept json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param username path string true "username of the user"
+// @Param preferences body models.PreferencesPatch true "preferences to set"
+// @Success 200 {object} models.Preferences "Preferences replaced."
+// @Failure 400 {object} models.ErrorResponse "Unknown preference, invalid language or theme."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Users may access their own preferences only."
+// @Failure 404 {object} models.ErrorResponse "User with given username not found."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /user/{username}/preferences [put]
+func (a *Adapter) setPreferences(ctx *gin.Context) {
+	a.writePreferences(ctx, a.preferencesSvc.SetPreferences)
+}
+
+// @ID updatePreferences
+// @tags user
+// @Summary Update user preferences
+// @Description Sets the given preferences and keeps the others. Returns the resulting preferences.
+// @Accept json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param username path string true "username of the user"
+// @Param preferences body models.PreferencesPatch true "preferences to change"
+// @Success 200 {object} models.Preferences "Preferences updated."
+// @Failure 400 {object} models.ErrorResponse "Unknown preference, invalid language or theme."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Users may access their own preferences only."
+// @Failure 404 {object} models.ErrorResponse "User with given username not found."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /user/{username}/preferences [patch]
+func (a *Adapter) updatePreferences(ctx *gin.Context) {
+	a.writePreferences(ctx, a.preferencesSvc.UpdatePreferences)
+}
+
+// writePreferences serves PUT and PATCH, which differ in the service method only.
+func (a *Adapter) writePreferences(ctx *gin.Context, write func(ctx context.Context, username string, prefs models.PreferencesPatch) (models.Preferences, error)) {
+	username, err := a.getUsernameFromPath(ctx)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	req, err := bindPreferences(ctx)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	prefs, err := write(ctx.Request.Context(), username, req)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, prefs)
+}
+
+// bindPreferences decodes the preferences from the request body. Unknown preferences are rejected, so that
+// a misspelled one is not silently ignored.
+func bindPreferences(ctx *gin.Context) (models.PreferencesPatch, error) {
+	var prefs models.PreferencesPatch
+	dec := json.NewDecoder(ctx.Request.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&prefs); err != nil {
+		// the decoder has no error type for unknown fields
+		if strings.HasPrefix(err.Error(), "json: unknown field") {
+			return prefs, models.ErrInvalidPreferences
+		}
+		return prefs, models.ErrBadRequest
+	}
+	return prefs, nil
+}
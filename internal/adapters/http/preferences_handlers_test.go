@@ -0,0 +1,38 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"user-service/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBindPreferences(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+	}{
+		{name: "empty object", body: `{}`},
+		{name: "known preferences", body: `{"language": "ru", "theme": "dark", "notifications": {"email": false, "sms": true, "push": true}}`},
+		{name: "unknown preference", body: `{"language": "ru", "timezone": "Europe/Moscow"}`, wantErr: models.ErrInvalidPreferences},
+		{name: "misspelled preference", body: `{"Theme ": "dark"}`, wantErr: models.ErrInvalidPreferences},
+		{name: "unknown notification channel", body: `{"notifications": {"telegram": true}}`, wantErr: models.ErrInvalidPreferences},
+		{name: "wrong type", body: `{"notifications": {"sms": "yes"}}`, wantErr: models.ErrBadRequest},
+		{name: "malformed", body: `{"theme": `, wantErr: models.ErrBadRequest},
+		{name: "no body", body: ``, wantErr: models.ErrBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest(http.MethodPatch, "/user/"+testUsername+"/preferences", strings.NewReader(tt.body))
+			if _, err := bindPreferences(ctx); !errors.Is(err, tt.wantErr) {
+				t.Errorf("bindPreferences(%s) = %v, want %v", tt.body, err, tt.wantErr)
+			}
+		})
+	}
+}
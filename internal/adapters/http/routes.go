@@ -1,29 +1,98 @@
 package http
 
 import (
+	"user-service/internal/domain/models"
 	"user-service/pkg/infra/logger"
+	"user-service/pkg/infra/metrics"
+	"user-service/pkg/infra/tracing"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	sloggin "github.com/samber/slog-gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func initRouter(a *Adapter, r *gin.Engine) error {
 	log := logger.Get()
 	log.Info("initializing handlers and routes...")
 
-	config := cors.DefaultConfig()
-	config.AllowAllOrigins = true
-	r.Use(cors.New(config))
-	r.Use(sloggin.New(log))
+	r.Use(otelgin.Middleware(tracing.ServiceName))
+	r.Use(a.requestID)
+	r.Use(a.measure)
+	r.Use(a.handleCORS)
+	r.Use(a.logRequest)
 
 	r.GET("swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	r.GET("/health", a.health)
-	r.POST("/user", a.createUser)
-	r.GET("/user/:username", a.getUser)
-	r.PUT("/user/:username", a.updateUser)
-	r.DELETE("/user/:username", a.deleteUser)
+	r.GET("/livez", a.livez)
+	r.GET("/readyz", a.readyz)
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// OpenID Connect endpoints called by clients authenticate the caller on their own.
+	r.GET("/.well-known/openid-configuration", a.oidcDiscovery)
+	r.GET("/oauth2/jwks", a.oidcJWKS)
+	r.POST("/oauth2/token", a.rateLimit, a.oidcToken)
+	r.GET("/oauth2/userinfo", a.rateLimit, a.oidcUserInfo)
+	r.POST("/oauth2/userinfo", a.rateLimit, a.oidcUserInfo)
+
+	api := r.Group("", a.authenticate, a.rateLimit)
+	api.POST("/auth/login", a.login)
+	api.POST("/auth/login/2fa", a.loginSecondFactor)
+	api.GET("/oauth2/authorize", a.oidcAuthorize)
+	api.POST("/oauth2/authorize", a.oidcAuthorizeLogin)
+
+	protected := api.Group("", a.csrfProtect)
+	protected.POST("/user", a.optionalScope(models.ScopeUsersWrite), a.createUser)
+	protected.GET("/users", a.requireAdmin, a.listUsers)
+	protected.GET("/user/:username", a.requireScope(models.ScopeUsersRead), a.getUser)
+	protected.PUT("/user/:username", a.requireScope(models.ScopeUsersWrite), a.updateUser)
+	protected.DELETE("/user/:username", a.requireScope(models.ScopeUsersWrite), a.deleteUser)
+	protected.POST("/user/:username/restore", a.requireAdmin, a.restoreUser)
+	protected.GET("/user/:username/status", a.requireAdmin, a.getUserStatus)
+	protected.PUT("/user/:username/status", a.requireAdmin, a.setUserStatus)
+	protected.GET("/user/:username/audit", a.requireAdmin, a.getUserAudit)
+	protected.GET("/user/:username/preferences", a.requireScope(models.ScopeUsersRead), a.getPreferences)
+	protected.PUT("/user/:username/preferences", a.requireScope(models.ScopeUsersWrite), a.setPreferences)
+	protected.PATCH("/user/:username/preferences", a.requireScope(models.ScopeUsersWrite), a.updatePreferences)
+	protected.GET("/user/:username/avatar", a.optionalScope(models.ScopeUsersRead), a.getAvatar)
+	protected.PUT("/user/:username/avatar", a.requireScope(models.ScopeUsersWrite), a.setAvatar)
+
+	// the same operations addressing users by their stable IDs; the administrative ones are documented
+	// under /user/{username} only
+	byID := protected.Group("/user/id/:id")
+	byID.GET("", a.requireScope(models.ScopeUsersRead), a.resolveUserID, a.getUserByID)
+	byID.PUT("", a.requireScope(models.ScopeUsersWrite), a.resolveUserID, a.updateUserByID)
+	byID.DELETE("", a.requireScope(models.ScopeUsersWrite), a.resolveUserID, a.deleteUserByID)
+	byID.POST("/restore", a.requireAdmin, a.resolveUserID, a.restoreUser)
+	byID.GET("/status", a.requireAdmin, a.resolveUserID, a.getUserStatus)
+	byID.PUT("/status", a.requireAdmin, a.resolveUserID, a.setUserStatus)
+	byID.GET("/audit", a.requireAdmin, a.resolveUserID, a.getUserAudit)
+	byID.GET("/preferences", a.requireScope(models.ScopeUsersRead), a.resolveUserID, a.getPreferences)
+	byID.PUT("/preferences", a.requireScope(models.ScopeUsersWrite), a.resolveUserID, a.setPreferences)
+	byID.PATCH("/preferences", a.requireScope(models.ScopeUsersWrite), a.resolveUserID, a.updatePreferences)
+	byID.GET("/avatar", a.optionalScope(models.ScopeUsersRead), a.resolveUserID, a.getAvatarByID)
+	byID.PUT("/avatar", a.requireScope(models.ScopeUsersWrite), a.resolveUserID, a.setAvatar)
+
+	protected.POST("/auth/logout", a.requireAuth, a.logout)
+	protected.GET("/auth/sessions", a.requireAuth, a.listSessions)
+	protected.DELETE("/auth/sessions/:id", a.requireAuth, a.revokeSession)
+	protected.POST("/auth/2fa/enroll", a.requireAuth, a.enrollTOTP)
+	protected.POST("/auth/2fa/confirm", a.requireAuth, a.confirmTOTP)
+	protected.DELETE("/auth/2fa", a.requireAuth, a.disableTOTP)
+
+	admin := protected.Group("/admin", a.requireAdmin)
+	admin.POST("/api-keys", a.createAPIKey)
+	admin.GET("/api-keys", a.listAPIKeys)
+	admin.DELETE("/api-keys/:id", a.revokeAPIKey)
+	admin.POST("/oauth/clients", a.createOAuthClient)
+	admin.GET("/oauth/clients", a.listOAuthClients)
+	admin.DELETE("/oauth/clients/:client_id", a.deleteOAuthClient)
+	admin.GET("/config/reload", a.getConfigReload)
+	admin.POST("/config/reload", a.reloadConfig)
+	admin.GET("/log-level", a.getLogLevel)
+	admin.PUT("/log-level", a.setLogLevel)
+	admin.GET("/user-attributes", a.listAttributeDefinitions)
+	admin.PUT("/user-attributes/:name", a.setAttributeDefinition)
+	admin.DELETE("/user-attributes/:name", a.deleteAttributeDefinition)
 	return nil
 }
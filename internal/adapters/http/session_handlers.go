@@ -0,0 +1,129 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"user-service/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	sessionCookie = "session_id"
+	csrfCookie    = "csrf_token"
+	csrfHeader    = "X-CSRF-Token"
+)
+
+// @ID listSessions
+// @tags auth
+// @Summary List active sessions
+// @Description Returns the active sessions (devices) of the current user. The session the request was made with is marked as current.
+// @Security BearerAuth
+// @Success 200 {array} models.Session "Active sessions."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /auth/sessions [get]
+func (a *Adapter) listSessions(ctx *gin.Context) {
+	principal, _ := getPrincipal(ctx)
+	sessions, err := a.sessionSvc.ListSessions(ctx.Request.Context(), principal.Username, principal.SessionID)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, sessions)
+}
+
+// @ID revokeSession
+// @tags auth
+// @Summary Revoke session
+// @Description Logs the current user out on the device with the given session id. Requests made with a session cookie must pass the CSRF token in the X-CSRF-Token header.
+// @Security BearerAuth
+// @Param id path string true "id of the session to revoke"
+// @Success 200 {object} models.SuccessResponse "Session revoked."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Missing or invalid CSRF token."
+// @Failure 404 {object} models.ErrorResponse "Session not found."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /auth/sessions/{id} [delete]
+func (a *Adapter) revokeSession(ctx *gin.Context) {
+	principal, _ := getPrincipal(ctx)
+	id := ctx.Param("id")
+	err := a.sessionSvc.RevokeSession(ctx.Request.Context(), principal.Username, id)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	if id == principal.SessionID {
+		a.clearSessionCookies(ctx)
+	}
+	ctx.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Success: fmt.Sprintf("session '%s' revoked", id)},
+	)
+}
+
+// @ID logout
+// @tags auth
+// @Summary Log out
+// @Description Ends the current session and clears the session cookies. Bearer tokens can not be revoked and simply expire.
+// @Security BearerAuth
+// @Success 200 {object} models.SuccessResponse "Logged out."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Missing or invalid CSRF token."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /auth/logout [post]
+func (a *Adapter) logout(ctx *gin.Context) {
+	principal, _ := getPrincipal(ctx)
+	if principal.SessionID != "" {
+		err := a.sessionSvc.RevokeSession(ctx.Request.Context(), principal.Username, principal.SessionID)
+		if err != nil {
+			a.ErrorHandler(ctx, err)
+			return
+		}
+		a.clearSessionCookies(ctx)
+	}
+	ctx.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Success: "logged out"},
+	)
+}
+
+// respondLogin completes a successful login. In session mode it starts a session instead of returning
+// the access token.
+func (a *Adapter) respondLogin(ctx *gin.Context, resp models.LoginResponse) {
+	if resp.MFARequired || a.opts.AuthMode != models.AuthModeSession {
+		ctx.JSON(http.StatusOK, resp)
+		return
+	}
+	session, csrfToken, err := a.startSession(ctx, resp.Principal)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.LoginResponse{
+		ExpiresIn: int64(session.ExpiresAt.Sub(session.CreatedAt).Seconds()),
+		CSRFToken: csrfToken,
+	})
+}
+
+// startSession creates a session for the user and sets the HttpOnly session cookie and the CSRF cookie,
+// which scripts read to fill the X-CSRF-Token header.
+func (a *Adapter) startSession(ctx *gin.Context, principal models.Principal) (models.Session, string, error) {
+	sessionToken, session, err := a.sessionSvc.CreateSession(ctx.Request.Context(), principal, ctx.Request.UserAgent(), a.clientIP(ctx))
+	if err != nil {
+		return models.Session{}, "", err
+	}
+	csrfToken := a.sessionSvc.CSRFToken(sessionToken)
+	maxAge := int(a.opts.SessionMaxLifetime.Seconds())
+
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(sessionCookie, sessionToken, maxAge, "/", "", a.opts.SessionCookieSecure, true)
+	ctx.SetCookie(csrfCookie, csrfToken, maxAge, "/", "", a.opts.SessionCookieSecure, false)
+	return session, csrfToken, nil
+}
+
+func (a *Adapter) clearSessionCookies(ctx *gin.Context) {
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(sessionCookie, "", -1, "/", "", a.opts.SessionCookieSecure, true)
+	ctx.SetCookie(csrfCookie, "", -1, "/", "", a.opts.SessionCookieSecure, false)
+}
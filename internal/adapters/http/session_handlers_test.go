@@ -0,0 +1,241 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"user-service/internal/domain/models"
+	"user-service/internal/domain/usecases"
+	"user-service/internal/ports"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// sessionStorage keeps the sessions in memory, by token hash.
+type sessionStorage struct {
+	ports.SessionStorage
+	mu       sync.Mutex
+	sessions map[string]models.Session
+}
+
+func (s *sessionStorage) SaveSession(_ context.Context, session models.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[session.TokenHash] = session
+	return nil
+}
+
+func (s *sessionStorage) GetSessionByToken(_ context.Context, tokenHash string) (models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[tokenHash]
+	if !ok {
+		return models.Session{}, errors.New("no rows")
+	}
+	return session, nil
+}
+
+func (s *sessionStorage) ListSessions(_ context.Context, username string) ([]models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var sessions []models.Session
+	for _, session := range s.sessions {
+		if session.Username == username {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (s *sessionStorage) DeleteSession(_ context.Context, username, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for hash, session := range s.sessions {
+		if session.Username == username && session.ID == id {
+			delete(s.sessions, hash)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *sessionStorage) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
+
+// sessionServer is the service in session mode.
+type sessionServer struct {
+	router   *gin.Engine
+	adapter  *Adapter
+	sessions *sessionStorage
+}
+
+func newSessionServer(t *testing.T, secureCookies bool) *sessionServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &userStorage{
+		user: models.GetUserResponse{ID: testUserID, Username: testUsername, Status: models.UserStatusActive},
+		cred: models.Credentials{
+			UserID: testUserID, Username: testUsername, PasswordHash: string(hash), Role: models.RoleUser, Status: models.UserStatusActive,
+		},
+	}
+	sessions := &sessionStorage{sessions: map[string]models.Session{}}
+	a := &Adapter{
+		opts: AdapterOptions{
+			AuthMode:            models.AuthModeSession,
+			SessionCookieSecure: secureCookies,
+			SessionMaxLifetime:  720 * time.Hour,
+		},
+		authSvc: usecases.NewAuthSvc(users, usecases.AuthOptions{Secret: "secret", TokenTTL: time.Hour, MFATokenTTL: time.Minute}),
+		sessionSvc: usecases.NewSessionSvc(sessions, usecases.SessionOptions{
+			Secret: "secret", IdleTTL: 24 * time.Hour, MaxLifetime: 720 * time.Hour,
+		}),
+	}
+	a.SetCORSOrigins(nil)
+	router := gin.New()
+	if err = initRouter(a, router); err != nil {
+		t.Fatal(err)
+	}
+	return &sessionServer{router: router, adapter: a, sessions: sessions}
+}
+
+// do serves the request with the cookies and headers given as name and value pairs.
+func (s *sessionServer) do(method, path string, cookies []*http.Cookie, headers ...string) *httptest.ResponseRecorder {
+	body := ""
+	if path == "/auth/login" {
+		body = `{"username":"` + testUsername + `","password":"` + testPassword + `"}`
+	}
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+// login starts a session and returns its cookies by name and the CSRF token from the response.
+func (s *sessionServer) login(t *testing.T) (map[string]*http.Cookie, string) {
+	t.Helper()
+	w := s.do(http.MethodPost, "/auth/login", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login = %d: %s", w.Code, w.Body)
+	}
+	var resp models.LoginResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.AccessToken != "" {
+		t.Errorf("session login returned an access token")
+	}
+	cookies := map[string]*http.Cookie{}
+	for _, c := range w.Result().Cookies() {
+		cookies[c.Name] = c
+	}
+	return cookies, resp.CSRFToken
+}
+
+func TestSessionCookies(t *testing.T) {
+	for _, secure := range []bool{true, false} {
+		t.Run(map[bool]string{true: "secure", false: "insecure"}[secure], func(t *testing.T) {
+			s := newSessionServer(t, secure)
+			cookies, csrfToken := s.login(t)
+
+			session, csrf := cookies[sessionCookie], cookies[csrfCookie]
+			if session == nil || csrf == nil {
+				t.Fatalf("cookies %v, want %s and %s", cookies, sessionCookie, csrfCookie)
+			}
+			for _, c := range []*http.Cookie{session, csrf} {
+				if c.Secure != secure || c.SameSite != http.SameSiteLaxMode || c.Path != "/" || c.MaxAge != 720*3600 || c.Value == "" {
+					t.Errorf("cookie %s: secure %t, same site %v, path %q, max age %d, want secure %t, lax, /, %d",
+						c.Name, c.Secure, c.SameSite, c.Path, c.MaxAge, secure, 720*3600)
+				}
+			}
+			// scripts read the CSRF token, but never the session token
+			if !session.HttpOnly || csrf.HttpOnly {
+				t.Errorf("HttpOnly: %s %t, %s %t, want true and false", sessionCookie, session.HttpOnly, csrfCookie, csrf.HttpOnly)
+			}
+			if csrf.Value != csrfToken || csrf.Value == session.Value {
+				t.Errorf("CSRF cookie %q, response %q, want the same token, different from the session token", csrf.Value, csrfToken)
+			}
+		})
+	}
+}
+
+func TestCSRFProtection(t *testing.T) {
+	s := newSessionServer(t, true)
+	cookies, csrfToken := s.login(t)
+	session := []*http.Cookie{cookies[sessionCookie]}
+	otherCookies, otherToken := s.login(t)
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		cookies []*http.Cookie
+		headers []string
+		want    int
+	}{
+		{name: "safe method without token", method: http.MethodGet, path: "/auth/sessions", cookies: session, want: http.StatusOK},
+		{name: "missing token", method: http.MethodPost, path: "/auth/logout", cookies: session, want: http.StatusForbidden},
+		{name: "token in cookie only", method: http.MethodPost, path: "/auth/logout", cookies: append(session, cookies[csrfCookie]), want: http.StatusForbidden},
+		{name: "token of another session", method: http.MethodPost, path: "/auth/logout", cookies: session, headers: []string{csrfHeader, otherToken}, want: http.StatusForbidden},
+		{name: "session token as csrf token", method: http.MethodPost, path: "/auth/logout", cookies: session, headers: []string{csrfHeader, cookies[sessionCookie].Value}, want: http.StatusForbidden},
+		{name: "delete without token", method: http.MethodDelete, path: "/auth/sessions/x", cookies: session, want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := s.do(tt.method, tt.path, tt.cookies, tt.headers...); w.Code != tt.want {
+				t.Errorf("%s %s = %d, want %d: %s", tt.method, tt.path, w.Code, tt.want, w.Body)
+			}
+		})
+	}
+	if n := s.sessions.count(); n != 2 {
+		t.Fatalf("%d sessions after rejected requests, want 2", n)
+	}
+
+	// requests with credentials in the Authorization header can not be forged and are not checked
+	login, err := s.adapter.authSvc.Login(context.Background(), models.LoginRequest{Username: testUsername, Password: testPassword})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := s.do(http.MethodPost, "/auth/logout", session, "Authorization", "Bearer "+login.AccessToken)
+	if w.Code != http.StatusOK || s.sessions.count() != 2 {
+		t.Errorf("bearer logout = %d with %d sessions, want 200 and the session cookie ignored", w.Code, s.sessions.count())
+	}
+
+	w = s.do(http.MethodPost, "/auth/logout", session, csrfHeader, csrfToken)
+	if w.Code != http.StatusOK || s.sessions.count() != 1 {
+		t.Fatalf("logout = %d with %d sessions, want 200 and the session ended", w.Code, s.sessions.count())
+	}
+	for _, c := range w.Result().Cookies() {
+		if c.MaxAge >= 0 || c.Value != "" {
+			t.Errorf("cookie %s = %q, max age %d after logout, want it cleared", c.Name, c.Value, c.MaxAge)
+		}
+	}
+	// the ended session is no longer accepted, and its cookies are cleared
+	w = s.do(http.MethodGet, "/auth/sessions", session)
+	if w.Code != http.StatusUnauthorized || len(w.Result().Cookies()) != 2 {
+		t.Errorf("request with the ended session = %d, cookies %v, want 401 and the cookies cleared", w.Code, w.Result().Cookies())
+	}
+	// the other session is not affected
+	if w = s.do(http.MethodPost, "/auth/logout", []*http.Cookie{otherCookies[sessionCookie]}, csrfHeader, otherToken); w.Code != http.StatusOK {
+		t.Errorf("logout of the other session = %d", w.Code)
+	}
+}
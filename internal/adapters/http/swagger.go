@@ -7,3 +7,11 @@ package http
 // @description A service that stores information about users.
 // @contact.name Olga Shishkina
 // @contact.email olenka.shishkina.02@mail.ru
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Access token from /auth/login in the form "Bearer <token>".
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name Authorization
+// @description API key for service-to-service calls in the form "ApiKey <key>".
@@ -0,0 +1,80 @@
+package http
+
+import "github.com/gin-gonic/gin"
+
+// The /user/id/:id routes resolve the ID to the current username with resolveUserID and are served by
+// the /user/:username handlers. The functions below document them.
+
+// @ID getUserByID
+// @tags user
+// @Summary Get user by ID
+// @Description Returns information about the user with the given ID. Unlike the username, the ID never changes.
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "ID of the user to get"
+// @Success 200 {object} models.GetUserResponse "User data received successfully."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "API key without the users:read scope."
+// @Failure 404 {object} models.ErrorResponse "User with given ID not found."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /user/id/{id} [get]
+func (a *Adapter) getUserByID(ctx *gin.Context) {
+	a.getUser(ctx)
+}
+
+// @ID updateUserByID
+// @tags user
+// @Summary Update user by ID
+// @Description Updates user data of the user with the given ID, including the username.
+// @Accept json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "ID of the user to update"
+// @Param user body models.User true "user data"
+// @Success 200 {object} models.SuccessResponse "User information updated successfully."
+// @Failure 400 {object} models.ErrorResponse "Missing required 'user' parameter / undefined or invalid attributes."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Users may change their own account only / users that are not active can not change their own account."
+// @Failure 404 {object} models.ErrorResponse "User with given ID not found."
+// @Failure 409 {object} models.ErrorResponse "New username is confusable with an existing one / was recently released by another user / conflicts with another user after normalization, choose another username."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /user/id/{id} [put]
+func (a *Adapter) updateUserByID(ctx *gin.Context) {
+	a.updateUser(ctx)
+}
+
+// @ID deleteUserByID
+// @tags user
+// @Summary Delete user by ID
+// @Description Deletes the user with the given ID and ends the user's sessions, like DELETE /user/{username}.
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "ID of the user to delete"
+// @Success 200 {object} models.SuccessResponse "User deleted successfully."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Users may delete their own account only / API key without the users:write scope."
+// @Failure 404 {object} models.ErrorResponse "User with given ID not found."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /user/id/{id} [delete]
+func (a *Adapter) deleteUserByID(ctx *gin.Context) {
+	a.deleteUser(ctx)
+}
+
+// @ID getAvatarByID
+// @tags user
+// @Summary Get avatar by user ID
+// @Description Returns the thumbnail of the avatar of the user with the given ID; 'avatar_url' of users points here. Responses to the URL with the current version may be cached forever, the others are revalidated with the ETag.
+// @Produce jpeg
+// @Produce png
+// @Param id path string true "ID of the user"
+// @Param size query int false "thumbnail size in pixels: 512, 256, 128 or 64" default(256)
+// @Param v query string false "avatar version"
+// @Success 200 {file} file "Avatar image."
+// @Success 304 "Not modified."
+// @Failure 400 {object} models.ErrorResponse "Unknown size."
+// @Failure 404 {object} models.ErrorResponse "User with given ID not found / user has no avatar."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /user/id/{id}/avatar [get]
+func (a *Adapter) getAvatarByID(ctx *gin.Context) {
+	a.getAvatar(ctx)
+}
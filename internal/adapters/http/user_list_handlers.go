@@ -0,0 +1,88 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"user-service/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// attributeParamPrefix starts the query parameters filtering users by custom attributes.
+const attributeParamPrefix = "attr."
+
+// @ID listUsers
+// @tags admin
+// @Summary List users
+// @Description Returns the users, oldest first, optionally filtered by the time ranges of their creation, last change and last login, and by custom attributes. Time bounds are in RFC 3339 format; '_from' bounds are inclusive, '_to' bounds are exclusive. Attribute filters are given as 'attr.<name>=<value>' (e.g. attr.department=sales) and match the string value or, for values like 42, true or null, the JSON value; several filters must all match.
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param created_from query string false "created at or after" format(date-time)
+// @Param created_to query string false "created before" format(date-time)
+// @Param updated_from query string false "changed at or after" format(date-time)
+// @Param updated_to query string false "changed before" format(date-time)
+// @Param last_login_from query string false "last logged in at or after" format(date-time)
+// @Param last_login_to query string false "last logged in before" format(date-time)
+// @Param limit query int false "page size, 50 by default, at most 200"
+// @Param offset query int false "number of users to skip"
+// @Param attr.{name} query string false "value of the custom attribute"
+// @Success 200 {array} models.GetUserResponse "Users."
+// @Failure 400 {object} models.ErrorResponse "Invalid query parameter."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Access denied / two-factor authentication required."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /users [get]
+func (a *Adapter) listUsers(ctx *gin.Context) {
+	var (
+		filter models.UserFilter
+		err    error
+	)
+	bounds := map[string]**time.Time{
+		"created_from":    &filter.CreatedFrom,
+		"created_to":      &filter.CreatedTo,
+		"updated_from":    &filter.UpdatedFrom,
+		"updated_to":      &filter.UpdatedTo,
+		"last_login_from": &filter.LastLoginFrom,
+		"last_login_to":   &filter.LastLoginTo,
+	}
+	for name, bound := range bounds {
+		s := ctx.Query(name)
+		if s == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			a.ErrorHandler(ctx, models.ErrBadRequest)
+			return
+		}
+		*bound = &t
+	}
+	for key, values := range ctx.Request.URL.Query() {
+		if name, ok := strings.CutPrefix(key, attributeParamPrefix); ok {
+			if filter.Attributes == nil {
+				filter.Attributes = map[string]string{}
+			}
+			filter.Attributes[name] = values[0]
+		}
+	}
+	if s := ctx.Query("limit"); s != "" {
+		if filter.Limit, err = strconv.Atoi(s); err != nil || filter.Limit < 0 {
+			a.ErrorHandler(ctx, models.ErrBadRequest)
+			return
+		}
+	}
+	if s := ctx.Query("offset"); s != "" {
+		if filter.Offset, err = strconv.Atoi(s); err != nil || filter.Offset < 0 {
+			a.ErrorHandler(ctx, models.ErrBadRequest)
+			return
+		}
+	}
+	users, err := a.userSvc.ListUsers(ctx.Request.Context(), filter)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, users)
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"net/http"
+	"user-service/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @ID getUserStatus
+// @tags admin
+// @Summary User status
+// @Description Returns the status of the user with the reason and time of the last change.
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param username path string true "username of the user"
+// @Success 200 {object} models.UserStatus "User status."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Access denied / two-factor authentication required."
+// @Failure 404 {object} models.ErrorResponse "User with given username not found."
+// @Router /user/{username}/status [get]
+func (a *Adapter) getUserStatus(ctx *gin.Context) {
+	username, err := a.getUsernameFromPath(ctx)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	status, err := a.userSvc.GetUserStatus(ctx.Request.Context(), username)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, status)
+}
+
+// @ID setUserStatus
+// @tags admin
+// @Summary Change user status
+// @Description Changes the status of the user. Allowed transitions: pending → active or deactivated; active → suspended or deactivated; suspended → active or deactivated; deactivated → active. Suspension and deactivation require a reason and end the user's sessions; only active users can log in, use issued tokens or change their own account.
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Accept json
+// @Param username path string true "username of the user"
+// @Param request body models.SetUserStatusRequest true "new status and the reason of the change"
+// @Success 200 {object} models.UserStatus "Status changed."
+// @Failure 400 {object} models.ErrorResponse "Unknown status / missing reason."
+// @Failure 401 {object} models.ErrorResponse "Authentication required."
+// @Failure 403 {object} models.ErrorResponse "Access denied / two-factor authentication required."
+// @Failure 404 {object} models.ErrorResponse "User with given username not found."
+// @Failure 409 {object} models.ErrorResponse "Transition from the current status is not allowed."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Router /user/{username}/status [put]
+func (a *Adapter) setUserStatus(ctx *gin.Context) {
+	username, err := a.getUsernameFromPath(ctx)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	var req models.SetUserStatusRequest
+	if err = ctx.ShouldBindJSON(&req); err != nil {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+	status, err := a.userSvc.SetUserStatus(ctx.Request.Context(), username, req)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, status)
+}
@@ -3,16 +3,43 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"time"
+	"user-service/internal/adapters/blob"
 	"user-service/internal/adapters/db"
 	"user-service/internal/adapters/http"
+	"user-service/internal/config"
+	"user-service/internal/domain/models"
 	"user-service/internal/domain/usecases"
+	"user-service/pkg/infra/logger"
+	"user-service/pkg/infra/metrics"
+	"user-service/pkg/infra/tracing"
+	"user-service/pkg/ratelimit"
+	"user-service/pkg/token"
 )
 
 type App struct {
-	opts          AppOptions
-	shutdownFuncs []func(ctx context.Context) error
+	opts       AppOptions
+	health     *usecases.HealthSvc
+	limiter    *ratelimit.Limiter
+	server     *http.Adapter
+	components []component                     // started components, in order of start
+	startFn    func(ctx context.Context) error // starts the components, app.start unless replaced in tests
+	errc       <-chan error
+
+	reloadMu      sync.Mutex
+	runtimeConfig *config.Config // the config the runtime settings were last applied from
+	lastReload    *models.ConfigReloadResult
+}
+
+// component is a started part of the application that must be stopped on shutdown.
+type component struct {
+	name string
+	stop func(ctx context.Context) error
 }
 
 type AppOptions struct {
@@ -20,53 +47,282 @@ type AppOptions struct {
 	HTTP_port   int
 	Timeout     time.Duration
 	IdleTimeout time.Duration
+	AuthSecret  string
+	TokenTTL    time.Duration
+	MFATokenTTL time.Duration
+	TOTPIssuer  string
+
+	AdminRequireMFA bool
+
+	DBMaxConns          int
+	DBMinConns          int
+	DBHealthCheckPeriod time.Duration
+	DBMaxConnLifetime   time.Duration
+	DBMaxConnIdleTime   time.Duration
+	DBStartupTimeout    time.Duration
+
+	OIDCIssuer         string
+	OIDCSigningKeyFile string
+	OIDCCodeTTL        time.Duration
+	OIDCTokenTTL       time.Duration
+
+	AuthMode            string
+	SessionIdleTTL      time.Duration
+	SessionMaxLifetime  time.Duration
+	SessionCookieSecure bool
+
+	RateLimits       string
+	RateLimitBackend string
+	CORSOrigins      []string
+	TrustedProxies   []string
+
+	UserInitialStatus    string
+	DeletedUserRetention time.Duration
+	UserPurgeInterval    time.Duration
+
+	UsernameRedirectPeriod time.Duration
+	UsernameReusePeriod    time.Duration
+
+	AvatarDir       string
+	AvatarMaxSize   int
+	AvatarMaxPixels int
+
+	HealthCheckTimeout time.Duration
+	ShutdownDrainDelay time.Duration
+	ShutdownTimeout    time.Duration
+
+	TracingExporter    string
+	OTLPEndpoint       string
+	OTLPInsecure       bool
+	TracingSampleRatio float64
+
+	Reload ReloadOptions
 }
 
 // New returns a new application instance.
 func New(opts AppOptions) *App {
-	return &App{
-		opts: opts,
+	app := &App{
+		opts:          opts,
+		runtimeConfig: opts.Reload.Initial,
+	}
+	app.startFn = app.start
+	return app
+}
+
+// Start starts the components in order: tracing, storage, HTTP server, and returns once the service
+// is ready to accept requests. If a component fails to start or a dependency is unhealthy after start,
+// the components already started are stopped and the error is returned.
+func (app *App) Start(ctx context.Context) (err error) {
+	defer func() {
+		if err == nil {
+			return
+		}
+		stopCtx, cancel := context.WithTimeout(context.Background(), app.opts.ShutdownTimeout)
+		defer cancel()
+		if stopErr := app.stopComponents(stopCtx); stopErr != nil {
+			err = errors.Join(err, stopErr)
+		}
+	}()
+
+	if err = app.startFn(ctx); err != nil {
+		return err
+	}
+	resp := app.health.Ready(ctx)
+	var failed []string
+	for name, check := range resp.Checks {
+		if check.Status != models.HealthStatusUp {
+			failed = append(failed, fmt.Sprintf("%s: %s", name, check.Error))
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("service is not healthy after start: %s", strings.Join(failed, "; "))
 	}
+	app.health.SetStarted()
+	logger.Get().Info("service started")
+	return nil
+}
+
+// Err returns the channel receiving the error if a component fails after start.
+func (app *App) Err() <-chan error {
+	return app.errc
 }
 
-// Start creates the database and service instances, then builds and starts the adapter.
-func (app *App) Start() error {
+func (app *App) start(ctx context.Context) error {
+	if app.opts.AuthMode != models.AuthModeToken && app.opts.AuthMode != models.AuthModeSession {
+		return fmt.Errorf("unknown auth mode %q", app.opts.AuthMode)
+	}
+
+	shutdownTracing, err := tracing.New(ctx, tracing.TracingOptions{
+		Exporter:     app.opts.TracingExporter,
+		OTLPEndpoint: app.opts.OTLPEndpoint,
+		OTLPInsecure: app.opts.OTLPInsecure,
+		SampleRatio:  app.opts.TracingSampleRatio,
+	})
+	if err != nil {
+		return fmt.Errorf("tracing setup failed: %w", err)
+	}
+	app.components = append(app.components, component{name: "tracing", stop: shutdownTracing})
+
 	// creates the database and service instances
-	storage, err := db.New(context.Background(), app.opts.DB_url)
+	storage, err := db.New(ctx, db.DBOptions{
+		URL:               app.opts.DB_url,
+		MaxConns:          int32(app.opts.DBMaxConns),
+		MinConns:          int32(app.opts.DBMinConns),
+		HealthCheckPeriod: app.opts.DBHealthCheckPeriod,
+		MaxConnLifetime:   app.opts.DBMaxConnLifetime,
+		MaxConnIdleTime:   app.opts.DBMaxConnIdleTime,
+		StartupTimeout:    app.opts.DBStartupTimeout,
+	})
 	if err != nil {
 		return fmt.Errorf("storage creation failed: %w", err)
 	}
-	userService := usecases.New(storage)
+	poolCollector := storage.NewPoolCollector()
+	app.components = append(app.components, component{name: "storage", stop: func(context.Context) error {
+		metrics.Registry.Unregister(poolCollector)
+		storage.Close()
+		return nil
+	}})
+	if err = metrics.Registry.Register(poolCollector); err != nil {
+		return fmt.Errorf("pool metrics registration failed: %w", err)
+	}
+	userService := usecases.New(storage, usecases.UserOptions{
+		InitialStatus:          app.opts.UserInitialStatus,
+		UsernameRedirectPeriod: app.opts.UsernameRedirectPeriod,
+		UsernameReusePeriod:    app.opts.UsernameReusePeriod,
+	})
+	authService := usecases.NewAuthSvc(storage, usecases.AuthOptions{
+		Secret:      app.opts.AuthSecret,
+		TokenTTL:    app.opts.TokenTTL,
+		MFATokenTTL: app.opts.MFATokenTTL,
+		TOTPIssuer:  app.opts.TOTPIssuer,
+	})
+	apiKeyService := usecases.NewAPIKeySvc(storage)
+	preferencesService := usecases.NewPreferencesSvc(storage)
+
+	avatarBlobs, err := blob.NewLocal(app.opts.AvatarDir)
+	if err != nil {
+		return fmt.Errorf("avatar storage creation failed: %w", err)
+	}
+	avatarService := usecases.NewAvatarSvc(storage, avatarBlobs, usecases.AvatarOptions{
+		MaxPixels: app.opts.AvatarMaxPixels,
+	})
+
+	signingKey, err := token.LoadRSAKey(app.opts.OIDCSigningKeyFile)
+	if err != nil {
+		return fmt.Errorf("loading oidc signing key failed: %w", err)
+	}
+	if app.opts.OIDCSigningKeyFile == "" {
+		logger.Get().Warn("oidc signing key is not configured, using a temporary key: issued tokens will not survive a restart")
+	}
+	oidcService := usecases.NewOIDCSvc(storage, storage, token.NewRSASigner(signingKey), usecases.OIDCOptions{
+		Issuer:   app.opts.OIDCIssuer,
+		CodeTTL:  app.opts.OIDCCodeTTL,
+		TokenTTL: app.opts.OIDCTokenTTL,
+	})
+
+	app.limiter, err = app.newRateLimiter(storage)
+	if err != nil {
+		return fmt.Errorf("rate limiter initialization failed: %w", err)
+	}
 
 	// instantiate the adapter
 	optsAdapter := http.AdapterOptions{
-		HTTP_port:   app.opts.HTTP_port,
-		Timeout:     app.opts.Timeout,
-		IdleTimeout: app.opts.IdleTimeout,
+		HTTP_port:       app.opts.HTTP_port,
+		Timeout:         app.opts.Timeout,
+		IdleTimeout:     app.opts.IdleTimeout,
+		AdminRequireMFA: app.opts.AdminRequireMFA,
+
+		AuthMode:            app.opts.AuthMode,
+		SessionMaxLifetime:  app.opts.SessionMaxLifetime,
+		SessionCookieSecure: app.opts.SessionCookieSecure,
+
+		RateLimiter: app.limiter,
+		CORSOrigins: app.opts.CORSOrigins,
+
+		TrustedProxies: app.opts.TrustedProxies,
+
+		AvatarMaxSize: int64(app.opts.AvatarMaxSize),
+	}
+	sessionService := usecases.NewSessionSvc(storage, usecases.SessionOptions{
+		Secret:      app.opts.AuthSecret,
+		IdleTTL:     app.opts.SessionIdleTTL,
+		MaxLifetime: app.opts.SessionMaxLifetime,
+	})
+
+	app.health = usecases.NewHealthSvc(storage, usecases.HealthOptions{
+		CheckTimeout: app.opts.HealthCheckTimeout,
+	})
+
+	services := http.Services{
+		User:        userService,
+		Auth:        authService,
+		APIKey:      apiKeyService,
+		OIDC:        oidcService,
+		Session:     sessionService,
+		Health:      app.health,
+		Config:      app,
+		Preferences: preferencesService,
+		Avatar:      avatarService,
 	}
-	s, err := http.New(userService, optsAdapter)
+	s, err := http.New(services, optsAdapter)
 	if err != nil {
 		return fmt.Errorf("adapter initialization failed: %w", err)
 	}
 
-	// add the application stop function to the list of shutdown functions and start the service.
-	app.shutdownFuncs = append(app.shutdownFuncs, s.Stop)
-	err = s.Start()
-	if err != nil {
+	// the adapter holds the listener from now on, so it is stopped even if serving fails to start.
+	app.components = append(app.components, component{name: "http server", stop: s.Stop})
+	if err = s.Start(); err != nil {
 		return fmt.Errorf("server start failed: %w", err)
 	}
+	app.errc = s.Err()
+	app.server = s
 
+	app.components = append(app.components, component{name: "config watcher", stop: app.watchConfig()})
+	app.components = append(app.components, component{name: "user purger", stop: app.purgeDeletedUsers(userService, avatarService)})
 	return nil
 }
 
-// Stop executes all shutdown functions.
-func (a *App) Stop(ctx context.Context) error {
-	var err error
-	for i := len(a.shutdownFuncs) - 1; i >= 0; i-- {
-		err = a.shutdownFuncs[i](ctx)
-		if err != nil {
-			return err
+// newRateLimiter builds the rate limiter with the configured backend. Requests are not limited while
+// there are no rules; the rules may be changed on reload.
+func (app *App) newRateLimiter(storage *db.DBStorage) (*ratelimit.Limiter, error) {
+	rules, err := ratelimit.ParseRules(app.opts.RateLimits)
+	if err != nil {
+		return nil, err
+	}
+	var store ratelimit.Store
+	switch app.opts.RateLimitBackend {
+	case "memory":
+		store = ratelimit.NewMemoryStore()
+	case "postgres":
+		store = db.NewRateLimitStore(storage)
+	default:
+		return nil, fmt.Errorf("unknown rate limit backend %q", app.opts.RateLimitBackend)
+	}
+	return ratelimit.NewLimiter(store, rules), nil
+}
+
+// Stop marks the service as not ready, waits for load balancers to notice it and stops the components
+// in reverse order of start: the HTTP server first, then the storage. Errors of all components are returned.
+func (app *App) Stop(ctx context.Context) error {
+	if app.health != nil {
+		app.health.SetShuttingDown()
+		select {
+		case <-time.After(app.opts.ShutdownDrainDelay):
+		case <-ctx.Done():
 		}
 	}
-	return nil
+	return app.stopComponents(ctx)
+}
+
+func (app *App) stopComponents(ctx context.Context) error {
+	var errs []error
+	for i := len(app.components) - 1; i >= 0; i-- {
+		c := app.components[i]
+		if err := c.stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stopping %s failed: %w", c.name, err))
+		}
+	}
+	app.components = nil
+	return errors.Join(errs...)
 }
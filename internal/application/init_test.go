@@ -0,0 +1,116 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"user-service/internal/domain/usecases"
+)
+
+// healthStorage reports the given dependency state to the readiness check.
+type healthStorage struct {
+	pingErr error
+}
+
+func (s healthStorage) Ping(context.Context) error {
+	return s.pingErr
+}
+
+func (s healthStorage) PendingMigrations(context.Context) ([]string, error) {
+	return nil, nil
+}
+
+// recorder builds components that record the order they are stopped in.
+type recorder struct {
+	stopped []string
+}
+
+func (r *recorder) component(name string, err error) component {
+	return component{name: name, stop: func(context.Context) error {
+		r.stopped = append(r.stopped, name)
+		return err
+	}}
+}
+
+func newTestApp() *App {
+	return New(AppOptions{ShutdownTimeout: time.Second, HealthCheckTimeout: time.Second})
+}
+
+func TestStopStopsComponentsInReverseOrder(t *testing.T) {
+	errStorage := errors.New("storage close failed")
+	errServer := errors.New("server shutdown failed")
+	r := &recorder{}
+	app := newTestApp()
+	app.components = []component{
+		r.component("tracing", nil),
+		r.component("storage", errStorage),
+		r.component("http server", errServer),
+		r.component("user purger", nil),
+	}
+
+	err := app.Stop(context.Background())
+	if got, want := strings.Join(r.stopped, ","), "user purger,http server,storage,tracing"; got != want {
+		t.Errorf("stopped %s, want %s", got, want)
+	}
+	if !errors.Is(err, errStorage) || !errors.Is(err, errServer) {
+		t.Errorf("Stop() = %v, want both component errors", err)
+	}
+	for _, want := range []string{"stopping storage failed", "stopping http server failed"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Stop() = %v, want it to contain %q", err, want)
+		}
+	}
+	if len(app.components) != 0 {
+		t.Errorf("%d components left after stop", len(app.components))
+	}
+	if err = app.Stop(context.Background()); err != nil || len(r.stopped) != 4 {
+		t.Errorf("second Stop() = %v and stopped components again", err)
+	}
+}
+
+func TestStartStopsStartedComponentsOnFailure(t *testing.T) {
+	errStart := errors.New("adapter initialization failed")
+	errStop := errors.New("storage close failed")
+	tests := []struct {
+		name     string
+		startErr error  // returned after starting the components
+		pingErr  error  // reported by the readiness check after start
+		stopErr  error  // returned by the storage on stop
+		wantErr  string // empty if Start succeeds
+	}{
+		{name: "started and healthy"},
+		{name: "component fails to start", startErr: errStart, wantErr: "adapter initialization failed"},
+		{name: "unhealthy after start", pingErr: errors.New("connection refused"), wantErr: "service is not healthy after start: postgres: connection refused"},
+		{name: "stop errors are joined", startErr: errStart, stopErr: errStop, wantErr: "stopping storage failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{}
+			app := newTestApp()
+			app.startFn = func(context.Context) error {
+				app.components = append(app.components, r.component("tracing", nil), r.component("storage", tt.stopErr))
+				app.health = usecases.NewHealthSvc(healthStorage{pingErr: tt.pingErr}, usecases.HealthOptions{CheckTimeout: time.Second})
+				return tt.startErr
+			}
+
+			err := app.Start(context.Background())
+			if tt.wantErr == "" {
+				if err != nil || len(r.stopped) != 0 {
+					t.Fatalf("Start() = %v, stopped %v", err, r.stopped)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Start() = %v, want %q", err, tt.wantErr)
+			}
+			if tt.startErr != nil && !errors.Is(err, tt.startErr) || tt.stopErr != nil && !errors.Is(err, tt.stopErr) {
+				t.Errorf("Start() = %v, want the start and stop errors", err)
+			}
+			if got, want := strings.Join(r.stopped, ","), "storage,tracing"; got != want {
+				t.Errorf("stopped %s, want %s", got, want)
+			}
+		})
+	}
+}
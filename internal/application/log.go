@@ -0,0 +1,29 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"user-service/internal/domain/models"
+	"user-service/pkg/infra/logger"
+)
+
+// LogLevel returns the current log level.
+func (app *App) LogLevel() models.LogLevel {
+	return models.LogLevel{Level: strings.ToLower(logger.Level().String())}
+}
+
+// SetLogLevel changes the log level until the next change of LOG_LEVEL in the config or restart.
+func (app *App) SetLogLevel(ctx context.Context, level string) (models.LogLevel, error) {
+	if level == "" {
+		return models.LogLevel{}, models.ErrInvalidLogLevel
+	}
+	l, err := logger.ParseLevel(level, false)
+	if err != nil {
+		return models.LogLevel{}, models.ErrInvalidLogLevel
+	}
+	prev := app.LogLevel()
+	logger.SetLevel(l)
+	res := app.LogLevel()
+	logger.FromContext(ctx).Info("log level changed", "from", prev.Level, "to", res.Level)
+	return res, nil
+}
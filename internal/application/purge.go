@@ -0,0 +1,55 @@
+package application
+
+import (
+	"context"
+	"time"
+	"user-service/internal/domain/usecases"
+	"user-service/pkg/infra/logger"
+)
+
+// purgeDeletedUsers periodically removes the users deleted longer than the retention period ago, their
+// avatars and the expired username history. It returns the function that stops purging. Replicas purge independently, which is harmless.
+func (app *App) purgeDeletedUsers(userService *usecases.UserSvc, avatarService *usecases.AvatarSvc) func(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(app.opts.UserPurgeInterval)
+		defer ticker.Stop()
+		for {
+			n, err := userService.PurgeDeletedUsers(ctx, app.opts.DeletedUserRetention)
+			switch {
+			case err != nil && ctx.Err() == nil:
+				logger.Get().Error("purging deleted users failed", "desc", err.Error())
+			case n > 0:
+				logger.Get().Info("deleted users purged", "count", n)
+			}
+			if n, err := avatarService.PurgeAvatars(ctx); err != nil && ctx.Err() == nil {
+				logger.Get().Error("purging avatars failed", "desc", err.Error())
+			} else if n > 0 {
+				logger.Get().Info("avatars of purged users deleted", "count", n)
+			}
+			if n, err := userService.PurgeUsernameHistory(ctx); err != nil && ctx.Err() == nil {
+				logger.Get().Error("purging username history failed", "desc", err.Error())
+			} else if n > 0 {
+				logger.Get().Debug("username history purged", "count", n)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func(stopCtx context.Context) error {
+		cancel()
+		select {
+		case <-stopped:
+			return nil
+		case <-stopCtx.Done():
+			return stopCtx.Err()
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"user-service/internal/config"
+	"user-service/internal/domain/models"
+	"user-service/pkg/infra/logger"
+	"user-service/pkg/ratelimit"
+)
+
+// configPollInterval is how often the config file is checked for changes. Polling, unlike file system
+// events, also notices files replaced through symlinks, as in mounted Kubernetes ConfigMaps.
+const configPollInterval = 2 * time.Second
+
+var errReloadDisabled = errors.New("config reloading is disabled")
+
+// reloadable lists the settings that are applied without restart.
+var reloadable = map[string]bool{
+	"LOG_LEVEL":    true,
+	"RATE_LIMITS":  true,
+	"CORS_ORIGINS": true,
+}
+
+type ReloadOptions struct {
+	Load      func() (*config.Config, error) // reads the config from its sources again, nil disables reloading
+	Initial   *config.Config                 // the config the application was started with
+	WatchFile string                         // config file watched for changes, empty to reload on SIGHUP only
+}
+
+// ReloadConfig reads the config again and applies the runtime settings. Nothing is applied if the new
+// config is invalid. Changes of the other settings are reported as requiring a restart.
+func (app *App) ReloadConfig(ctx context.Context, trigger string) models.ConfigReloadResult {
+	app.reloadMu.Lock()
+	defer app.reloadMu.Unlock()
+
+	log := logger.FromContext(ctx)
+	res := models.ConfigReloadResult{
+		Trigger:         trigger,
+		Time:            time.Now(),
+		Changed:         []string{},
+		RequiresRestart: []string{},
+	}
+	defer func() { app.lastReload = &res }()
+
+	cfg, err := app.loadRuntimeConfig()
+	if err != nil {
+		res.Error = err.Error()
+		log.Error("config reload failed", "trigger", trigger, "desc", err.Error())
+		return res
+	}
+	if err = app.applyRuntimeConfig(cfg); err != nil {
+		res.Error = err.Error()
+		log.Error("config reload failed", "trigger", trigger, "desc", err.Error())
+		return res
+	}
+
+	for _, name := range config.Diff(app.runtimeConfig, cfg) {
+		if reloadable[name] {
+			res.Changed = append(res.Changed, name)
+		}
+	}
+	for _, name := range config.Diff(app.opts.Reload.Initial, cfg) {
+		if !reloadable[name] {
+			res.RequiresRestart = append(res.RequiresRestart, name)
+		}
+	}
+	app.runtimeConfig = cfg
+	res.Success = true
+
+	log.Info("config reloaded", "trigger", trigger, "changed", res.Changed)
+	if len(res.RequiresRestart) > 0 {
+		log.Warn("changed settings take effect after restart", "settings", res.RequiresRestart)
+	}
+	return res
+}
+
+// LastConfigReload returns the result of the last reload.
+func (app *App) LastConfigReload() (models.ConfigReloadResult, error) {
+	app.reloadMu.Lock()
+	defer app.reloadMu.Unlock()
+	if app.lastReload == nil {
+		return models.ConfigReloadResult{}, models.ErrNoConfigReload
+	}
+	return *app.lastReload, nil
+}
+
+func (app *App) loadRuntimeConfig() (*config.Config, error) {
+	if app.opts.Reload.Load == nil {
+		return nil, errReloadDisabled
+	}
+	return app.opts.Reload.Load()
+}
+
+// applyRuntimeConfig parses all runtime settings before applying any, so that either all of them
+// or none are changed. Each component swaps its setting atomically. The log level is set only if
+// LOG_LEVEL changed, so that a level set by an admin survives unrelated reloads.
+func (app *App) applyRuntimeConfig(cfg *config.Config) error {
+	level, err := logger.ParseLevel(cfg.LogLevel, cfg.IsProd)
+	if err != nil {
+		return err
+	}
+	rules, err := ratelimit.ParseRules(cfg.RateLimits)
+	if err != nil {
+		return err
+	}
+
+	if app.runtimeConfig == nil || app.runtimeConfig.LogLevel != cfg.LogLevel || app.runtimeConfig.IsProd != cfg.IsProd {
+		logger.SetLevel(level)
+	}
+	app.limiter.SetRules(rules)
+	app.server.SetCORSOrigins(cfg.CORSOriginList())
+	return nil
+}
+
+// watchConfig reloads the config on SIGHUP and when the watched config file changes. It returns the
+// function that stops watching.
+func (app *App) watchConfig() func(ctx context.Context) error {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	path := app.opts.Reload.WatchFile
+
+	go func() {
+		defer close(stopped)
+		var poll <-chan time.Time // nil blocks forever if there is no file to watch
+		if path != "" {
+			ticker := time.NewTicker(configPollInterval)
+			defer ticker.Stop()
+			poll = ticker.C
+		}
+		last, _ := statFile(path)
+		for {
+			select {
+			case <-done:
+				return
+			case <-sighup:
+				app.ReloadConfig(context.Background(), models.ReloadTriggerSignal)
+			case <-poll:
+				state, err := statFile(path)
+				if err != nil || state == last {
+					continue
+				}
+				last = state
+				app.ReloadConfig(context.Background(), models.ReloadTriggerFile)
+			}
+		}
+	}()
+
+	return func(ctx context.Context) error {
+		signal.Stop(sighup)
+		close(done)
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// fileState identifies a version of the file.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) (fileState, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}, err
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}, nil
+}
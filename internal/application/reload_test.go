@@ -0,0 +1,110 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+	"user-service/internal/adapters/http"
+	"user-service/internal/config"
+	"user-service/internal/domain/models"
+	"user-service/pkg/infra/logger"
+	"user-service/pkg/ratelimit"
+)
+
+// newReloadApp returns an app started with the initial config, that reloads the config *next points to.
+func newReloadApp(initial config.Config, next **config.Config) *App {
+	app := New(AppOptions{
+		ShutdownTimeout:    time.Second,
+		HealthCheckTimeout: time.Second,
+		Reload: ReloadOptions{
+			Load:    func() (*config.Config, error) { return *next, nil },
+			Initial: &initial,
+		},
+	})
+	rules, _ := ratelimit.ParseRules(initial.RateLimits)
+	app.limiter = ratelimit.NewLimiter(ratelimit.NewMemoryStore(), rules)
+	app.server = &http.Adapter{}
+	app.server.SetCORSOrigins(initial.CORSOriginList())
+	return app
+}
+
+// burst returns the burst of the limit applied to the route, 0 if it is not limited.
+func burst(t *testing.T, l *ratelimit.Limiter, route string) int {
+	t.Helper()
+	res, ok, err := l.Allow(context.Background(), route, t.Name()+time.Now().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		return 0
+	}
+	return res.Limit
+}
+
+func TestReloadConfig(t *testing.T) {
+	defer logger.SetLevel(logger.Level())
+	logger.SetLevel(slog.LevelInfo)
+
+	initial := config.Config{
+		HTTP_port:   3000,
+		LogLevel:    "info",
+		CORSOrigins: "*",
+		RateLimits:  "default=10/s:20",
+		DB_URL:      "postgres://postgres@db:5432/users",
+	}
+	next := &initial
+	app := newReloadApp(initial, &next)
+	ctx := context.Background()
+
+	// a reloadable and a non-reloadable setting change
+	changed := initial
+	changed.LogLevel = "warn"
+	changed.RateLimits = "default=10/s:30"
+	changed.HTTP_port = 4000
+	next = &changed
+	res := app.ReloadConfig(ctx, models.ReloadTriggerAPI)
+	if !res.Success || res.Error != "" {
+		t.Fatalf("ReloadConfig() = %+v, want success", res)
+	}
+	if !slices.Equal(res.Changed, []string{"LOG_LEVEL", "RATE_LIMITS"}) || !slices.Equal(res.RequiresRestart, []string{"HTTP_PORT"}) {
+		t.Errorf("changed %q, requires restart %q, want LOG_LEVEL, RATE_LIMITS and HTTP_PORT", res.Changed, res.RequiresRestart)
+	}
+	if logger.Level() != slog.LevelWarn || burst(t, app.limiter, "GET /user") != 30 {
+		t.Errorf("log level %s, burst %d after reload, want the new settings", logger.Level(), burst(t, app.limiter, "GET /user"))
+	}
+
+	// the restart is still required after another reload that changes nothing
+	res = app.ReloadConfig(ctx, models.ReloadTriggerSignal)
+	if len(res.Changed) != 0 || !slices.Equal(res.RequiresRestart, []string{"HTTP_PORT"}) {
+		t.Errorf("changed %q, requires restart %q, want HTTP_PORT only", res.Changed, res.RequiresRestart)
+	}
+
+	// an invalid config is not applied, not even its valid runtime settings
+	invalid := changed
+	invalid.LogLevel = "error"
+	invalid.RateLimits = "default=ten/s"
+	next = &invalid
+	res = app.ReloadConfig(ctx, models.ReloadTriggerFile)
+	if res.Success || res.Error == "" {
+		t.Fatalf("ReloadConfig(invalid) = %+v, want an error", res)
+	}
+	if logger.Level() != slog.LevelWarn || burst(t, app.limiter, "GET /user") != 30 {
+		t.Errorf("log level %s, burst %d after a failed reload, want them unchanged", logger.Level(), burst(t, app.limiter, "GET /user"))
+	}
+	if last, err := app.LastConfigReload(); err != nil || last.Trigger != models.ReloadTriggerFile || last.Success {
+		t.Errorf("LastConfigReload() = %+v, %v, want the failed reload", last, err)
+	}
+}
+
+func TestReloadConfigDisabled(t *testing.T) {
+	app := newTestApp()
+	if _, err := app.LastConfigReload(); !errors.Is(err, models.ErrNoConfigReload) {
+		t.Errorf("LastConfigReload() before a reload = %v, want %v", err, models.ErrNoConfigReload)
+	}
+	if res := app.ReloadConfig(context.Background(), models.ReloadTriggerSignal); res.Success || res.Error != errReloadDisabled.Error() {
+		t.Errorf("ReloadConfig() = %+v, want %v", res, errReloadDisabled)
+	}
+}
@@ -0,0 +1,289 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// field describes a setting of Config.
+type field struct {
+	index  int
+	name   string // name of the environment variable
+	def    string
+	hasDef bool
+	secret string // "true" for secrets, "url" for URLs with credentials
+	kind   reflect.Type
+}
+
+// value is a raw setting value with the source it was taken from.
+type value struct {
+	raw    string
+	source string
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Load builds the config from the defaults, the config file, environment variables and the command-line
+// arguments, and validates it. All problems found are reported in the returned error.
+func Load(args []string) (*Config, error) {
+	fields := configFields()
+	values := make(map[string]value)
+	for _, f := range fields {
+		if f.hasDef {
+			values[f.name] = value{raw: f.def, source: "default"}
+		}
+	}
+
+	flagValues, err := parseFlags(fields, args)
+	if err != nil {
+		return nil, err
+	}
+	envValues, problems := readEnv(fields)
+
+	// the config file path itself may come from the environment or a flag
+	path := envValues["CONFIG_FILE"].raw
+	if v, ok := flagValues["CONFIG_FILE"]; ok {
+		path = v.raw
+	}
+	if path != "" {
+		fileValues, fileProblems := readFile(fields, path)
+		problems = append(problems, fileProblems...)
+		for name, v := range fileValues {
+			values[name] = v
+		}
+	}
+	for name, v := range envValues {
+		values[name] = v
+	}
+	for name, v := range flagValues {
+		values[name] = v
+	}
+
+	cfg := &Config{}
+	rv := reflect.ValueOf(cfg).Elem()
+	invalid := make(map[string]bool)
+	for _, f := range fields {
+		v, ok := values[f.name]
+		if !ok {
+			continue
+		}
+		if err := setField(rv.Field(f.index), v.raw); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s (from %s)", f.name, err.Error(), v.source))
+			invalid[f.name] = true
+		}
+	}
+	// values that failed to parse are not validated again
+	for _, p := range cfg.Validate() {
+		if name, _, _ := strings.Cut(p, ":"); !invalid[name] {
+			problems = append(problems, p)
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// ValidationError lists all problems found in the configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+func configFields() []field {
+	t := reflect.TypeOf(Config{})
+	fields := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, ok := sf.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+		def, hasDef := sf.Tag.Lookup("envDefault")
+		fields = append(fields, field{
+			index:  i,
+			name:   name,
+			def:    def,
+			hasDef: hasDef,
+			secret: sf.Tag.Get("secret"),
+			kind:   sf.Type,
+		})
+	}
+	return fields
+}
+
+// flagName returns the command-line flag name of the setting: HTTP_PORT -> http-port.
+func flagName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+// fileKey returns the config file key of the setting: HTTP_PORT -> http_port.
+func fileKey(name string) string {
+	return strings.ToLower(name)
+}
+
+// flagValue collects the raw value of a flag. Boolean settings may be given without a value.
+type flagValue struct {
+	name   string
+	isBool bool
+	values map[string]value
+}
+
+func (v *flagValue) String() string { return "" }
+
+func (v *flagValue) Set(s string) error {
+	v.values[v.name] = value{raw: s, source: "flag --" + flagName(v.name)}
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool { return v.isBool }
+
+func parseFlags(fields []field, args []string) (map[string]value, error) {
+	values := make(map[string]value)
+	fs := flag.NewFlagSet("user-service", flag.ContinueOnError)
+	for _, f := range fields {
+		usage := "overrides " + f.name
+		if f.hasDef {
+			usage += fmt.Sprintf(" (default %q)", f.def)
+		}
+		fs.Var(&flagValue{name: f.name, isBool: f.kind.Kind() == reflect.Bool, values: values}, flagName(f.name), usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	return values, nil
+}
+
+// readEnv reads the environment variables. A <NAME>_FILE variable names a file holding the value, which
+// takes precedence over the <NAME> variable.
+func readEnv(fields []field) (map[string]value, []string) {
+	values := make(map[string]value)
+	var problems []string
+	for _, f := range fields {
+		if raw, ok := os.LookupEnv(f.name); ok {
+			values[f.name] = value{raw: raw, source: "env " + f.name}
+		}
+		path, ok := os.LookupEnv(f.name + "_FILE")
+		if !ok {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s_FILE: %s", f.name, err.Error()))
+			continue
+		}
+		values[f.name] = value{raw: strings.TrimRight(string(content), "\r\n"), source: "file " + path}
+	}
+	return values, problems
+}
+
+// readFile reads the flat YAML or TOML config file.
+func readFile(fields []field, path string) (map[string]value, []string) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, []string{"config file: " + err.Error()}
+	}
+	doc := make(map[string]any)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &doc)
+	case ".toml":
+		err = toml.Unmarshal(content, &doc)
+	default:
+		return nil, []string{fmt.Sprintf("config file %s: unsupported format %q, expected .yaml, .yml or .toml", path, ext)}
+	}
+	if err != nil {
+		return nil, []string{fmt.Sprintf("config file %s: %s", path, err.Error())}
+	}
+
+	names := make(map[string]string, len(fields))
+	for _, f := range fields {
+		names[fileKey(f.name)] = f.name
+	}
+	values := make(map[string]value)
+	var problems []string
+	for key, v := range doc {
+		name, ok := names[strings.ToLower(key)]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("config file %s: unknown key %q", path, key))
+			continue
+		}
+		switch v := v.(type) {
+		case map[string]any, []any:
+			problems = append(problems, fmt.Sprintf("config file %s: %s must be a scalar value", path, key))
+		case nil:
+			values[name] = value{raw: "", source: "config file " + path}
+		default:
+			values[name] = value{raw: fmt.Sprint(v), source: "config file " + path}
+		}
+	}
+	sort.Strings(problems)
+	return values, problems
+}
+
+func setField(v reflect.Value, raw string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// Diff returns the names of the settings that differ between the configs.
+func Diff(a, b *Config) []string {
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	var names []string
+	for _, f := range configFields() {
+		if f.name == "CONFIG_FILE" || f.name == "PRINT_CONFIG" {
+			continue
+		}
+		if !reflect.DeepEqual(va.Field(f.index).Interface(), vb.Field(f.index).Interface()) {
+			names = append(names, f.name)
+		}
+	}
+	return names
+}
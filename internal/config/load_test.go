@@ -0,0 +1,185 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv unsets the settings that may be set in the environment running the tests.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, f := range configFields() {
+		for _, name := range []string{f.name, f.name + "_FILE"} {
+			t.Setenv(name, "")
+			os.Unsetenv(name)
+		}
+	}
+}
+
+// writeFile writes a file to a temporary directory and returns its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// problems returns the problems reported by Load.
+func problems(t *testing.T, err error) []string {
+	t.Helper()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Load() = %v, want a validation error", err)
+	}
+	return verr.Problems
+}
+
+func TestLoadDefaults(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.HTTP_port != 3000 || cfg.Timeout != 10*time.Second || cfg.IsProd || cfg.LogFormat != "json" || cfg.TracingSampleRatio != 1 {
+		t.Errorf("Load() = %+v, want the defaults", cfg)
+	}
+	if cfg.LogLevel != "" || cfg.DBPassword != "" {
+		t.Errorf("settings without a default are set: LOG_LEVEL %q, DB_PASSWORD %q", cfg.LogLevel, cfg.DBPassword)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	for _, file := range []struct{ name, content string }{
+		{"config.yaml", "http_port: 4000\ntimeout: 5s\nlog_format: text\nis_prod: true\nauth_secret: from-file\n"},
+		{"config.toml", "http_port = 4000\ntimeout = \"5s\"\nlog_format = \"text\"\nis_prod = true\nauth_secret = \"from-file\"\n"},
+	} {
+		t.Run(file.name, func(t *testing.T) {
+			clearEnv(t)
+			t.Setenv("CONFIG_FILE", writeFile(t, file.name, file.content))
+			t.Setenv("HTTP_PORT", "5000")
+			t.Setenv("LOG_LEVEL", "warn")
+
+			cfg, err := Load([]string{"--timeout=7s", "--log-level", "error"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tests := []struct {
+				name      string
+				got, want any
+			}{
+				{"default", cfg.IdleTimeout, 60 * time.Second},
+				{"file over default", cfg.LogFormat, "text"},
+				{"file boolean", cfg.IsProd, true},
+				{"file string", cfg.AuthSecret, "from-file"},
+				{"env over file", cfg.HTTP_port, 5000},
+				{"flag over file", cfg.Timeout, 7 * time.Second},
+				{"flag over env", cfg.LogLevel, "error"},
+			}
+			for _, tt := range tests {
+				if tt.got != tt.want {
+					t.Errorf("%s: %v, want %v", tt.name, tt.got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileFlag(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CONFIG_FILE", writeFile(t, "env.yaml", "http_port: 4000\n"))
+	cfg, err := Load([]string{"--config-file", writeFile(t, "flag.yaml", "http_port: 4001\n")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.HTTP_port != 4001 {
+		t.Errorf("HTTP_PORT = %d, want 4001 from the file given by the flag", cfg.HTTP_port)
+	}
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("AUTH_SECRET", "from-env")
+	t.Setenv("AUTH_SECRET_FILE", writeFile(t, "auth_secret", "from-file\n"))
+	t.Setenv("DB_PASSWORD_FILE", writeFile(t, "db_password", "pass word\r\n"))
+	t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", "db_password: from-config\n"))
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AuthSecret != "from-file" {
+		t.Errorf("AUTH_SECRET = %q, want the content of AUTH_SECRET_FILE", cfg.AuthSecret)
+	}
+	// only the trailing line break is removed
+	if cfg.DBPassword != "pass word" {
+		t.Errorf("DB_PASSWORD = %q, want the content of DB_PASSWORD_FILE", cfg.DBPassword)
+	}
+	// flags still take precedence
+	cfg, err = Load([]string{"--auth-secret=from-flag"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AuthSecret != "from-flag" {
+		t.Errorf("AUTH_SECRET = %q, want the flag", cfg.AuthSecret)
+	}
+
+	t.Setenv("AUTH_SECRET_FILE", filepath.Join(t.TempDir(), "missing"))
+	_, err = Load(nil)
+	if p := problems(t, err); len(p) != 1 || !strings.HasPrefix(p[0], "AUTH_SECRET_FILE: ") {
+		t.Errorf("problems = %q, want the missing AUTH_SECRET_FILE", p)
+	}
+}
+
+func TestLoadValidation(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", "http_port: 0\nhttp_prot: 3000\ndb_max_conns: [1]\n"))
+	t.Setenv("TIMEOUT", "ten seconds")
+	t.Setenv("IS_PROD", "true")
+	t.Setenv("SESSION_IDLE_TTL", "1000h")
+
+	_, err := Load(nil)
+	got := problems(t, err)
+	want := []string{
+		"config file",
+		"config file",
+		"TIMEOUT: invalid duration \"ten seconds\" (from env TIMEOUT)",
+		"HTTP_PORT: must be between 1 and 65535, got 0",
+		"AUTH_SECRET: must be changed from the default value in production",
+		"SESSION_MAX_LIFETIME: must not be less than SESSION_IDLE_TTL",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("problems = %q, want %q", got, want)
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("problem %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+	// the file problems name the key
+	if !strings.Contains(got[0], "db_max_conns must be a scalar value") || !strings.Contains(got[1], `unknown key "http_prot"`) {
+		t.Errorf("config file problems = %q", got[:2])
+	}
+	// a value that failed to parse is reported once, not also as not positive
+	if slices.Contains(got, "TIMEOUT: must be positive") {
+		t.Errorf("problems = %q, TIMEOUT is reported twice", got)
+	}
+
+	if _, err = Load([]string{"--http-port=3000", "extra"}); err == nil || !strings.Contains(err.Error(), "unexpected arguments: extra") {
+		t.Errorf("Load(extra argument) = %v", err)
+	}
+	if _, err = Load([]string{"--no-such-flag"}); err == nil {
+		t.Error("Load(unknown flag) succeeded")
+	}
+	t.Setenv("CONFIG_FILE", writeFile(t, "config.json", "{}"))
+	_, err = Load(nil)
+	if p := problems(t, err); !strings.Contains(p[0], "unsupported format") {
+		t.Errorf("problems = %q, want the unsupported format", p)
+	}
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// Print writes the config in the config file format (YAML), with secrets redacted.
+func (c *Config) Print(w io.Writer) error {
+	rv := reflect.ValueOf(c).Elem()
+	for _, f := range configFields() {
+		v := rv.Field(f.index)
+		var s string
+		switch {
+		case v.Type() == durationType:
+			s = strconv.Quote(time.Duration(v.Int()).String())
+		case v.Kind() == reflect.String:
+			s = strconv.Quote(redact(f.secret, v.String()))
+		default:
+			s = fmt.Sprint(v.Interface())
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s\n", fileKey(f.name), s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LogValue lists the settings with secrets redacted, so that the config can be logged.
+func (c *Config) LogValue() slog.Value {
+	rv := reflect.ValueOf(c).Elem()
+	fields := configFields()
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, f := range fields {
+		v := rv.Field(f.index)
+		switch {
+		case v.Type() == durationType:
+			attrs = append(attrs, slog.String(f.name, time.Duration(v.Int()).String()))
+		case v.Kind() == reflect.String:
+			attrs = append(attrs, slog.String(f.name, redact(f.secret, v.String())))
+		default:
+			attrs = append(attrs, slog.Any(f.name, v.Interface()))
+		}
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// redact masks a secret value. For URLs, only the password is masked.
+func redact(secret, s string) string {
+	switch {
+	case s == "":
+		return s
+	case secret == "true":
+		return redacted
+	case secret == "url":
+		u, err := url.Parse(s)
+		if err != nil {
+			return redacted
+		}
+		return u.Redacted()
+	}
+	return s
+}
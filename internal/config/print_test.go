@@ -0,0 +1,102 @@
+package config
+
+import (
+	"bytes"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// secretConfig returns the default config with all secrets set.
+func secretConfig(t *testing.T) *Config {
+	t.Helper()
+	clearEnv(t)
+	cfg, err := Load([]string{
+		"--db-url=postgres://postgres:url-password@db:5432/users",
+		"--db-password=db-password",
+		"--auth-secret=auth-secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestPrint(t *testing.T) {
+	cfg := secretConfig(t)
+	var buf bytes.Buffer
+	if err := cfg.Print(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, secret := range []string{"url-password", "db-password", "auth-secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("Print() shows the secret %q:\n%s", secret, out)
+		}
+	}
+	for _, line := range []string{
+		`db_url: "postgres://postgres:xxxxx@db:5432/users"`,
+		`db_password: "[REDACTED]"`,
+		`auth_secret: "[REDACTED]"`,
+		`http_port: 3000`,
+		`timeout: "10s"`,
+		`log_level: ""`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("Print() has no line %s:\n%s", line, out)
+		}
+	}
+
+	// the output is a config file that loads the same settings, except for the secrets
+	clearEnv(t)
+	t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", out))
+	loaded, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := Diff(cfg, loaded); !slices.Equal(diff, []string{"DB_URL", "AUTH_SECRET", "DB_PASSWORD"}) {
+		t.Errorf("printed config differs in %q, want the secrets only", diff)
+	}
+}
+
+func TestLogValue(t *testing.T) {
+	cfg := secretConfig(t)
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("config", "config", cfg)
+	out := buf.String()
+	for _, secret := range []string{"url-password", "db-password", "auth-secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("the logged config shows the secret %q: %s", secret, out)
+		}
+	}
+	for _, attr := range []string{
+		`"DB_URL":"postgres://postgres:xxxxx@db:5432/users"`,
+		`"DB_PASSWORD":"[REDACTED]"`,
+		`"AUTH_SECRET":"[REDACTED]"`,
+		`"HTTP_PORT":3000`,
+		`"TIMEOUT":"10s"`,
+	} {
+		if !strings.Contains(out, attr) {
+			t.Errorf("the logged config has no %s: %s", attr, out)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		secret, value, want string
+	}{
+		{"", "visible", "visible"},
+		{"true", "", ""},
+		{"true", "secret", redacted},
+		{"url", "postgres://user:secret@db/users", "postgres://user:xxxxx@db/users"},
+		{"url", "postgres://db/users", "postgres://db/users"},
+		{"url", "postgres://user:secret@db:port/users", redacted}, // unparsable
+	}
+	for _, tt := range tests {
+		if got := redact(tt.secret, tt.value); got != tt.want {
+			t.Errorf("redact(%q, %q) = %q, want %q", tt.secret, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// API key scopes.
+const (
+	ScopeUsersRead  = "users:read"
+	ScopeUsersWrite = "users:write"
+	ScopeAdmin      = "admin"
+)
+
+var APIKeyScopes = []string{ScopeUsersRead, ScopeUsersWrite, ScopeAdmin}
+
+type APIKey struct {
+	ID         int64      `json:"id" example:"1"`
+	Name       string     `json:"name" example:"billing-service"`
+	Prefix     string     `json:"prefix" example:"a1b2c3d4"`
+	Scopes     []string   `json:"scopes" example:"users:read"`
+	CreatedBy  string     `json:"created_by" example:"admin"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	KeyHash    string     `json:"-"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" example:"billing-service"`
+	Scopes    []string   `json:"scopes" example:"users:read"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // no expiration if omitted
+}
+
+// CreateAPIKeyResponse contains the key itself, which is shown only once.
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key" example:"usk_a1b2c3d4_9Zx..."`
+}
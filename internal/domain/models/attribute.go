@@ -0,0 +1,19 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AttributeDefinition allows users to have the custom attribute. Its values must match the JSON Schema.
+type AttributeDefinition struct {
+	Name        string          `json:"name" example:"timezone"`
+	Description string          `json:"description,omitempty" example:"IANA time zone of the user"`
+	Schema      json.RawMessage `json:"schema" swaggertype:"object"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+type SetAttributeDefinitionRequest struct {
+	Description string          `json:"description" example:"IANA time zone of the user"`
+	Schema      json.RawMessage `json:"schema" swaggertype:"object" binding:"required"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// Audited user actions.
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+	AuditActionPurge   = "purge"  // permanent removal after the retention period
+	AuditActionStatus  = "status" // status change
+)
+
+// Limits of the audit log page size.
+const (
+	AuditPageDefaultLimit = 50
+	AuditPageMaxLimit     = 200
+)
+
+// AuditEntry records a change of a user: who made it, from where and what changed.
+type AuditEntry struct {
+	ID        int64         `json:"id" example:"42"`
+	UserID    string        `json:"user_id" example:"018f3b6e-6c1a-7d2e-9a4b-3c5d7e9f1a2b"`
+	Username  string        `json:"username" example:"IvanIvanov2000"` // at the time of the change
+	Action    string        `json:"action" example:"update"`
+	Actor     string        `json:"actor" example:"admin"` // username, "key:<id>" for API keys, empty for anonymous requests
+	RequestID string        `json:"request_id" example:"0f8fad5b-d9cb-469f-a165-70867728950e"`
+	IP        string        `json:"ip" example:"192.0.2.1"`
+	Changes   []AuditChange `json:"changes"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// AuditChange is the change of a single field. The values of secret fields are omitted.
+type AuditChange struct {
+	Field string  `json:"field" example:"email"`
+	Old   *string `json:"old,omitempty" example:"iivanov@gmail.com"`
+	New   *string `json:"new,omitempty" example:"ivanov@example.com"`
+}
+
+// AuditPage is a page of the audit log, newest entries first.
+type AuditPage struct {
+	Entries    []AuditEntry `json:"entries"`
+	NextCursor int64        `json:"next_cursor,omitempty" example:"17"` // pass as 'cursor' to get the next page, omitted on the last page
+}
+
+// AuditActor identifies who makes the changes on behalf of a request.
+type AuditActor struct {
+	Name string // username, "key:<id>" for API keys, empty for anonymous requests
+	IP   string
+}
+
+type auditActorKey struct{}
+
+// WithAuditActor returns a copy of ctx carrying the actor recorded in the audit log.
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActorFromContext returns the actor carried by ctx, or the zero value if there is none.
+func AuditActorFromContext(ctx context.Context) AuditActor {
+	actor, _ := ctx.Value(auditActorKey{}).(AuditActor)
+	return actor
+}
@@ -0,0 +1,105 @@
+package models
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Authentication methods recorded in issued tokens.
+const (
+	AuthMethodPassword = "pwd"
+	AuthMethodOTP      = "otp"
+)
+
+// Credentials holds the authentication data of a user as kept in storage.
+type Credentials struct {
+	UserID       string
+	Username     string
+	PasswordHash string
+	Role         string
+	TOTPSecret   string
+	TOTPEnabled  bool
+	Status       string
+}
+
+// Principal describes the authenticated caller of a request: either a user or a service using an API key.
+type Principal struct {
+	UserID    string // empty for API keys
+	Username  string
+	Role      string
+	AMR       []string
+	APIKeyID  int64
+	Scopes    []string
+	SessionID string // set when the request is authenticated with a session cookie
+}
+
+func (p Principal) IsAPIKey() bool {
+	return p.APIKeyID != 0
+}
+
+func (p Principal) IsAdmin() bool {
+	if p.IsAPIKey() {
+		return p.HasScope(ScopeAdmin)
+	}
+	return p.Role == RoleAdmin
+}
+
+// HasScope reports whether the principal is allowed the scope. Scopes only restrict API keys,
+// users are limited by their role instead.
+func (p Principal) HasScope(scope string) bool {
+	if !p.IsAPIKey() {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// HasMFA reports whether the principal passed a second authentication factor.
+func (p Principal) HasMFA() bool {
+	for _, m := range p.AMR {
+		if m == AuthMethodOTP {
+			return true
+		}
+	}
+	return false
+}
+
+type LoginRequest struct {
+	Username string `json:"username" example:"IvanIvanov2000"`
+	Password string `json:"password" example:"qwerty1234"`
+}
+
+type LoginSecondFactorRequest struct {
+	MFAToken string `json:"mfa_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	Code     string `json:"code" example:"123456"` // TOTP code or one of the recovery codes
+}
+
+// LoginResponse contains either an access token or, when 2FA is enabled, a token for the second login step.
+// In session mode the access token is replaced with a session cookie.
+type LoginResponse struct {
+	AccessToken string    `json:"access_token,omitempty"`
+	TokenType   string    `json:"token_type,omitempty" example:"Bearer"`
+	ExpiresIn   int64     `json:"expires_in,omitempty" example:"3600"`
+	MFARequired bool      `json:"mfa_required,omitempty"`
+	MFAToken    string    `json:"mfa_token,omitempty"`
+	CSRFToken   string    `json:"csrf_token,omitempty"` // session mode: to be sent in the X-CSRF-Token header
+	Principal   Principal `json:"-"`                    // the logged-in user, set once login is complete
+}
+
+type TOTPEnrollResponse struct {
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	URI    string `json:"otpauth_uri" example:"otpauth://totp/user-service:IvanIvanov2000?secret=JBSWY3DPEHPK3PXP&issuer=user-service"`
+	QRCode []byte `json:"qr_png" swaggertype:"string" format:"base64"`
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code" example:"123456"`
+}
+
+type TOTPConfirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
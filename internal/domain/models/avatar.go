@@ -0,0 +1,40 @@
+package models
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+// AvatarSizes are the sizes in pixels of the square thumbnails made of uploaded avatars, largest first.
+var AvatarSizes = []int{512, 256, 128, 64}
+
+// AvatarDefaultSize is the size of the thumbnail served if the size is not requested.
+const AvatarDefaultSize = 256
+
+// Avatar describes the uploaded avatar of a user.
+type Avatar struct {
+	URL   string `json:"url" example:"/user/id/018f3b6e-6c1a-7d2e-9a4b-3c5d7e9f1a2b/avatar?v=9f86d081884c7d65"` // changes with each upload
+	Sizes []int  `json:"sizes" example:"512,256,128,64"`                                                        // pass as 'size' to get a thumbnail
+}
+
+// AvatarImage is a thumbnail of the avatar. The content must be closed.
+type AvatarImage struct {
+	Blob
+	ContentType string
+	Version     string // changes with each upload
+}
+
+// Blob is an object read from the blob storage.
+type Blob struct {
+	Content io.ReadSeekCloser
+	Size    int64
+	ModTime time.Time
+}
+
+// AvatarURL returns the URL of the avatar stored as "<version>.<extension>". The URL includes the version,
+// so that it may be cached for long.
+func AvatarURL(userID, avatar string) string {
+	version, _, _ := strings.Cut(avatar, ".")
+	return "/user/id/" + userID + "/avatar?v=" + version
+}
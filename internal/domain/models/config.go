@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Triggers of a config reload.
+const (
+	ReloadTriggerSignal = "signal" // SIGHUP
+	ReloadTriggerFile   = "file"   // the config file changed
+	ReloadTriggerAPI    = "api"    // admin request
+)
+
+// ConfigReloadResult is the outcome of reloading the settings that can be changed without restart:
+// the log level, rate limits and CORS origins.
+type ConfigReloadResult struct {
+	Trigger         string    `json:"trigger" example:"signal"`
+	Time            time.Time `json:"time"`
+	Success         bool      `json:"success"`
+	Error           string    `json:"error,omitempty"`
+	Changed         []string  `json:"changed" example:"LOG_LEVEL"`             // applied settings whose values changed
+	RequiresRestart []string  `json:"requires_restart" example:"DB_MAX_CONNS"` // changed settings that take effect only after restart
+}
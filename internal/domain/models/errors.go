@@ -4,13 +4,47 @@ package models
 import "fmt"
 
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty" example:"0f8fad5b-d9cb-469f-a165-70867728950e"` // quote it when reporting the problem
 }
 
 var (
-	ErrInvalidEmailFormat = fmt.Errorf("invalid format of parameter 'email'")    // 400
-	ErrInvalidPhoneFormat = fmt.Errorf("invalid format of parameter 'phone'")    // 400
-	ErrBadRequest         = fmt.Errorf("missing required parameters")            // 400
-	ErrUserAlreadyExists  = fmt.Errorf("user with this username already exists") // 400
-	ErrUserNotFound       = fmt.Errorf("user not found")                         // 404
+	ErrInvalidEmailFormat     = fmt.Errorf("invalid format of parameter 'email'")              // 400
+	ErrInvalidPhoneFormat     = fmt.Errorf("invalid format of parameter 'phone'")              // 400
+	ErrBadRequest             = fmt.Errorf("missing required parameters")                      // 400
+	ErrUserAlreadyExists      = fmt.Errorf("user with this username already exists")           // 400
+	ErrTOTPAlreadyEnabled     = fmt.Errorf("two-factor authentication already enabled")        // 400
+	ErrTOTPNotEnrolled        = fmt.Errorf("two-factor authentication not enrolled")           // 400
+	ErrInvalidScope           = fmt.Errorf("unknown api key scope")                            // 400
+	ErrInvalidRedirectURI     = fmt.Errorf("invalid redirect_uri")                             // 400
+	ErrInvalidUserStatus      = fmt.Errorf("unknown user status")                              // 400
+	ErrStatusReasonRequired   = fmt.Errorf("reason is required for this status")               // 400
+	ErrInvalidLogLevel        = fmt.Errorf("log level must be debug, info, warn or error")     // 400
+	ErrInvalidUsername        = fmt.Errorf("invalid username")                                 // 400
+	ErrInvalidAttributes      = fmt.Errorf("invalid attributes")                               // 400
+	ErrInvalidAttributeName   = fmt.Errorf("attribute name must match ^[a-z][a-z0-9_]{0,62}$") // 400
+	ErrInvalidAttributeSchema = fmt.Errorf("invalid attribute schema")                         // 400
+	ErrInvalidPreferences     = fmt.Errorf("invalid or unknown preference")                    // 400
+	ErrInvalidAvatarSize      = fmt.Errorf("unknown avatar size")                              // 400
+	ErrUnauthorized           = fmt.Errorf("authentication required")                          // 401
+	ErrInvalidCredentials     = fmt.Errorf("invalid username or password")                     // 401
+	ErrInvalidOTP             = fmt.Errorf("invalid one-time code")                            // 401
+	ErrForbidden              = fmt.Errorf("access denied")                                    // 403
+	ErrMFARequired            = fmt.Errorf("two-factor authentication required")               // 403
+	ErrUserNotActive          = fmt.Errorf("user account is not active")                       // 403
+	ErrInvalidCSRFToken       = fmt.Errorf("missing or invalid csrf token")                    // 403
+	ErrUserNotFound           = fmt.Errorf("user not found")                                   // 404
+	ErrAPIKeyNotFound         = fmt.Errorf("api key not found")                                // 404
+	ErrOAuthClientNotFound    = fmt.Errorf("oauth client not found")                           // 404
+	ErrSessionNotFound        = fmt.Errorf("session not found")                                // 404
+	ErrNoConfigReload         = fmt.Errorf("config has not been reloaded yet")                 // 404
+	ErrAttributeNotFound      = fmt.Errorf("attribute definition not found")                   // 404
+	ErrAvatarNotFound         = fmt.Errorf("avatar not found")                                 // 404
+	ErrStatusTransition       = fmt.Errorf("user status transition not allowed")               // 409
+	ErrUsernameReserved       = fmt.Errorf("username was recently used by another user")       // 409
+	ErrUsernameConfusable     = fmt.Errorf("username is confusable with existing username")    // 409
+	ErrUsernameRenameRequired = fmt.Errorf("username is confusable, rename required")          // 409
+	ErrAvatarTooLarge         = fmt.Errorf("avatar file or image is too large")                // 413
+	ErrUnsupportedAvatar      = fmt.Errorf("avatar must be a JPEG, PNG or GIF image")          // 415
+	ErrTooManyRequests        = fmt.Errorf("too many requests")                                // 429
 )
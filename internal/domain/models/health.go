@@ -0,0 +1,22 @@
+package models
+
+// Statuses of the service and its dependencies in the probe responses.
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+)
+
+// HealthResponse is the readiness probe report.
+type HealthResponse struct {
+	Status       string                       `json:"status" example:"up"`
+	Starting     bool                         `json:"starting,omitempty"`
+	ShuttingDown bool                         `json:"shutting_down,omitempty"`
+	Checks       map[string]HealthCheckResult `json:"checks,omitempty"`
+}
+
+// HealthCheckResult is the status of a single dependency.
+type HealthCheckResult struct {
+	Status    string  `json:"status" example:"up"`
+	LatencyMs float64 `json:"latency_ms" example:"1.25"`
+	Error     string  `json:"error,omitempty"`
+}
@@ -0,0 +1,6 @@
+package models
+
+// LogLevel is the minimum level of the messages written to the log.
+type LogLevel struct {
+	Level string `json:"level" example:"info"` // "debug", "info", "warn" or "error"
+}
@@ -0,0 +1,143 @@
+package models
+
+import "time"
+
+// OpenID Connect scopes supported by the provider.
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+	ScopePhone   = "phone"
+)
+
+var OIDCScopes = []string{ScopeOpenID, ScopeProfile, ScopeEmail, ScopePhone}
+
+// OAuthClient is an application registered to log users in through the service.
+type OAuthClient struct {
+	ClientID     string    `json:"client_id" example:"3f1a9c2e7b4d8f60"`
+	Name         string    `json:"name" example:"wiki"`
+	RedirectURIs []string  `json:"redirect_uris" example:"http://localhost:8085/callback"`
+	Public       bool      `json:"public"` // public clients (SPA, CLI) have no secret and must use PKCE
+	CreatedAt    time.Time `json:"created_at"`
+	SecretHash   string    `json:"-"`
+}
+
+type CreateOAuthClientRequest struct {
+	Name         string   `json:"name" example:"wiki"`
+	RedirectURIs []string `json:"redirect_uris" example:"http://localhost:8085/callback"`
+	Public       bool     `json:"public"`
+}
+
+// CreateOAuthClientResponse contains the client secret, which is shown only once.
+type CreateOAuthClientResponse struct {
+	OAuthClient
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// AuthorizeRequest holds the parameters of the authorization endpoint (RFC 6749, section 4.1.1, RFC 7636).
+type AuthorizeRequest struct {
+	ResponseType        string `form:"response_type"`
+	ClientID            string `form:"client_id"`
+	RedirectURI         string `form:"redirect_uri"`
+	Scope               string `form:"scope"`
+	State               string `form:"state"`
+	Nonce               string `form:"nonce"`
+	CodeChallenge       string `form:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method"`
+}
+
+// AuthorizationCode is a single-use code issued to the client after the user logs in.
+type AuthorizationCode struct {
+	CodeHash      string
+	ClientID      string
+	Username      string
+	RedirectURI   string
+	Scope         string
+	Nonce         string
+	CodeChallenge string
+	AuthTime      time.Time
+	ExpiresAt     time.Time
+}
+
+// TokenRequest holds the parameters of the token endpoint. Client credentials may also be passed
+// with HTTP basic authentication.
+type TokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+	CodeVerifier string `form:"code_verifier"`
+}
+
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type" example:"Bearer"`
+	ExpiresIn   int64  `json:"expires_in" example:"3600"`
+	IDToken     string `json:"id_token"`
+	Scope       string `json:"scope" example:"openid profile email"`
+}
+
+// UserInfo contains the standard OpenID Connect claims about the user.
+type UserInfo struct {
+	Subject           string `json:"sub" example:"018f3b6e-6c1a-7d2e-9a4b-3c5d7e9f1a2b"` // user ID
+	PreferredUsername string `json:"preferred_username,omitempty" example:"IvanIvanov2000"`
+	Name              string `json:"name,omitempty" example:"Ivan Ivanov"`
+	GivenName         string `json:"given_name,omitempty" example:"Ivan"`
+	FamilyName        string `json:"family_name,omitempty" example:"Ivanov"`
+	Email             string `json:"email,omitempty" example:"iivanov@gmail.com"`
+	PhoneNumber       string `json:"phone_number,omitempty" example:"+79999999999"`
+}
+
+// IDTokenClaims is the payload of the ID token. User claims are included according to the granted scopes.
+type IDTokenClaims struct {
+	Issuer   string `json:"iss"`
+	Audience string `json:"aud"`
+	IssuedAt int64  `json:"iat"`
+	Expires  int64  `json:"exp"`
+	AuthTime int64  `json:"auth_time"`
+	Nonce    string `json:"nonce,omitempty"`
+	UserInfo
+}
+
+// OIDCAccessTokenClaims is the payload of access tokens issued to clients for the userinfo endpoint.
+type OIDCAccessTokenClaims struct {
+	Issuer   string `json:"iss"`
+	Subject  string `json:"sub"`
+	ClientID string `json:"client_id"`
+	Scope    string `json:"scope"`
+	IssuedAt int64  `json:"iat"`
+	Expires  int64  `json:"exp"`
+}
+
+// DiscoveryDocument is the OpenID Provider metadata served at /.well-known/openid-configuration.
+type DiscoveryDocument struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// OAuthError is the error response format of the OAuth 2.0 endpoints (RFC 6749, section 5.2).
+type OAuthError struct {
+	Code        string `json:"error" example:"invalid_grant"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// NewOAuthError returns an OAuth error with the given code (e.g. "invalid_request") and description.
+func NewOAuthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
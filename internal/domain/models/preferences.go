@@ -0,0 +1,97 @@
+package models
+
+// Themes of the user interface.
+const (
+	ThemeLight  = "light"
+	ThemeDark   = "dark"
+	ThemeSystem = "system"
+)
+
+// Preferences are the user's settings, separate from the profile. Users have the defaults until they
+// change them.
+type Preferences struct {
+	Language      string                  `json:"language" example:"ru"` // BCP 47 language tag
+	Theme         string                  `json:"theme" example:"system" enums:"light,dark,system"`
+	Notifications NotificationPreferences `json:"notifications"`
+}
+
+// NotificationPreferences are the channels the user wants to be notified through.
+type NotificationPreferences struct {
+	Email bool `json:"email" example:"true"`
+	SMS   bool `json:"sms" example:"false"`
+	Push  bool `json:"push" example:"false"`
+}
+
+// DefaultPreferences returns the preferences of users that have not changed them.
+func DefaultPreferences() Preferences {
+	return Preferences{
+		Language: "en",
+		Theme:    ThemeSystem,
+		Notifications: NotificationPreferences{
+			Email: true,
+		},
+	}
+}
+
+// PreferencesPatch holds the preferences the user has set. Nil fields are not set: stored overrides fall
+// back to the defaults for them, and patches leave them unchanged.
+type PreferencesPatch struct {
+	Language      *string                       `json:"language,omitempty" example:"ru"`
+	Theme         *string                       `json:"theme,omitempty" example:"dark" enums:"light,dark,system"`
+	Notifications *NotificationPreferencesPatch `json:"notifications,omitempty"`
+}
+
+type NotificationPreferencesPatch struct {
+	Email *bool `json:"email,omitempty" example:"true"`
+	SMS   *bool `json:"sms,omitempty" example:"true"`
+	Push  *bool `json:"push,omitempty"`
+}
+
+// Merge returns the patch with the fields set in other replaced by their values.
+func (p PreferencesPatch) Merge(other PreferencesPatch) PreferencesPatch {
+	if other.Language != nil {
+		p.Language = other.Language
+	}
+	if other.Theme != nil {
+		p.Theme = other.Theme
+	}
+	if other.Notifications != nil {
+		n := NotificationPreferencesPatch{}
+		if p.Notifications != nil {
+			n = *p.Notifications
+		}
+		if other.Notifications.Email != nil {
+			n.Email = other.Notifications.Email
+		}
+		if other.Notifications.SMS != nil {
+			n.SMS = other.Notifications.SMS
+		}
+		if other.Notifications.Push != nil {
+			n.Push = other.Notifications.Push
+		}
+		p.Notifications = &n
+	}
+	return p
+}
+
+// Apply returns the preferences with the fields set in the patch replaced by their values.
+func (p PreferencesPatch) Apply(prefs Preferences) Preferences {
+	if p.Language != nil {
+		prefs.Language = *p.Language
+	}
+	if p.Theme != nil {
+		prefs.Theme = *p.Theme
+	}
+	if n := p.Notifications; n != nil {
+		if n.Email != nil {
+			prefs.Notifications.Email = *n.Email
+		}
+		if n.SMS != nil {
+			prefs.Notifications.SMS = *n.SMS
+		}
+		if n.Push != nil {
+			prefs.Notifications.Push = *n.Push
+		}
+	}
+	return prefs
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Authentication modes of the login endpoints.
+const (
+	AuthModeToken   = "token"   // bearer tokens returned in the response body
+	AuthModeSession = "session" // opaque session ids in cookies, sessions kept in storage
+)
+
+// Session is a server-side login session of a user on one device.
+type Session struct {
+	ID         string    `json:"id" example:"9f86d081884c7d65"`
+	UserID     string    `json:"-"`
+	Username   string    `json:"-"`
+	Role       string    `json:"-"`
+	AMR        []string  `json:"-"`
+	UserAgent  string    `json:"user_agent" example:"Mozilla/5.0"`
+	IP         string    `json:"ip" example:"192.168.0.10"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // the session the request was made with
+	TokenHash  string    `json:"-"`
+}
@@ -1,23 +1,71 @@
 package models
 
+import (
+	"log/slog"
+	"time"
+)
+
 type User struct {
+	ID        string `json:"-"` // generated by the service on creation
 	Username  string `json:"username" example:"IvanIvanov2000"`
 	Password  string `json:"password" example:"qwerty1234"`
 	FirstName string `json:"first_name" example:"Ivan"`
 	LastName  string `json:"last_name" example:"Ivanov"`
 	Email     string `json:"email" example:"iivanov@gmail.com"`
 	Phone     string `json:"phone" example:"+79999999999"`
+	Status    string `json:"-"` // set by the service on creation
+
+	Attributes map[string]any `json:"attributes,omitempty"` // custom attributes allowed by the attribute definitions
+}
+
+// LogValue keeps the password and contact details out of the logs.
+func (u User) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("username", u.Username))
 }
 
 // User without password
 type GetUserResponse struct {
+	ID        string `json:"id" example:"018f3b6e-6c1a-7d2e-9a4b-3c5d7e9f1a2b"` // stable across username changes
 	Username  string `json:"username" example:"IvanIvanov2000"`
 	FirstName string `json:"first_name" example:"Ivan"`
 	LastName  string `json:"last_name" example:"Ivanov"`
 	Email     string `json:"email" example:"iivanov@gmail.com"`
 	Phone     string `json:"phone" example:"+79999999999"`
+	Status    string `json:"status" example:"active"`
+
+	Attributes map[string]any `json:"attributes"`
+	AvatarURL  string         `json:"avatar_url,omitempty" example:"/user/id/018f3b6e-6c1a-7d2e-9a4b-3c5d7e9f1a2b/avatar?v=9f86d081884c7d65"` // omitted if the user has no avatar
+
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`              // last change of the user data or status
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"` // omitted if the user has never logged in
+}
+
+// UserFilter selects users by the time ranges of their timestamps and by attribute values. Nil bounds are not applied; From is
+// inclusive and To is exclusive.
+type UserFilter struct {
+	CreatedFrom, CreatedTo     *time.Time
+	UpdatedFrom, UpdatedTo     *time.Time
+	LastLoginFrom, LastLoginTo *time.Time
+
+	// attribute values by name; values that are JSON numbers, booleans or null also match the string
+	Attributes map[string]string
+
+	Limit  int
+	Offset int
 }
 
+// Limits of the user list page size.
+const (
+	UserPageDefaultLimit = 50
+	UserPageMaxLimit     = 200
+)
+
 type SuccessResponse struct {
 	Success string `json:"success"`
 }
+
+type CreateUserResponse struct {
+	Success string `json:"success"`
+	ID      string `json:"id" example:"018f3b6e-6c1a-7d2e-9a4b-3c5d7e9f1a2b"`
+}
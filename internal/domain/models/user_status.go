@@ -0,0 +1,41 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// User statuses.
+const (
+	UserStatusPending     = "pending"     // registered, awaiting activation
+	UserStatusActive      = "active"      // may log in
+	UserStatusSuspended   = "suspended"   // blocked by an administrator, e.g. for abuse
+	UserStatusDeactivated = "deactivated" // closed at the user's or an administrator's request
+)
+
+var UserStatuses = []string{UserStatusPending, UserStatusActive, UserStatusSuspended, UserStatusDeactivated}
+
+// UserStatus is the current status of a user with the reason and time of the last change.
+type UserStatus struct {
+	Status    string     `json:"status" example:"suspended"`
+	Reason    string     `json:"reason,omitempty" example:"spam"`
+	ChangedAt *time.Time `json:"changed_at,omitempty"` // omitted if the status has never changed
+}
+
+type SetUserStatusRequest struct {
+	Status string `json:"status" example:"suspended"`
+	Reason string `json:"reason" example:"spam"` // required for suspension and deactivation
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated caller.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the caller carried by ctx, if the request is authenticated.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,20 @@
+package usecases
+
+import (
+	"context"
+	"user-service/internal/domain/models"
+)
+
+// authorizeUserChange allows changing the user with the username, or reading the user's private data such as
+// preferences, to the user itself, to administrators and to API keys, whose scopes are checked by the HTTP
+// adapter. Requests without credentials are rejected.
+func authorizeUserChange(ctx context.Context, username string) error {
+	principal, ok := models.PrincipalFromContext(ctx)
+	switch {
+	case !ok:
+		return models.ErrUnauthorized
+	case principal.IsAPIKey(), principal.IsAdmin(), principal.Username == username:
+		return nil
+	}
+	return models.ErrForbidden
+}
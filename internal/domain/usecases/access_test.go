@@ -0,0 +1,42 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"user-service/internal/domain/models"
+)
+
+const testUsername = "IvanIvanov2000"
+
+// asUser returns the context of a request made by the user with the username.
+func asUser(username string) context.Context {
+	return models.WithPrincipal(context.Background(), models.Principal{Username: username, Role: models.RoleUser})
+}
+
+func TestAuthorizeUserChange(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *models.Principal // nil for requests without credentials
+		wantErr   error
+	}{
+		{name: "anonymous", wantErr: models.ErrUnauthorized},
+		{name: "owner", principal: &models.Principal{Username: testUsername, Role: models.RoleUser}},
+		{name: "another user", principal: &models.Principal{Username: "PetrPetrov1990", Role: models.RoleUser}, wantErr: models.ErrForbidden},
+		{name: "another user with a similar name", principal: &models.Principal{Username: "ivanivanov2000", Role: models.RoleUser}, wantErr: models.ErrForbidden},
+		{name: "administrator", principal: &models.Principal{Username: "admin", Role: models.RoleAdmin}},
+		{name: "api key", principal: &models.Principal{Username: "api-key:crm", APIKeyID: 1, Scopes: []string{models.ScopeUsersWrite}}},
+		{name: "api key named as the user", principal: &models.Principal{Username: testUsername, APIKeyID: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = models.WithPrincipal(ctx, *tt.principal)
+			}
+			if err := authorizeUserChange(ctx, testUsername); !errors.Is(err, tt.wantErr) {
+				t.Errorf("authorizeUserChange() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
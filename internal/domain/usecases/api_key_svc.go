@@ -0,0 +1,121 @@
+package usecases
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+	"user-service/internal/domain/models"
+	"user-service/internal/ports"
+	"user-service/pkg/infra/logger"
+)
+
+// apiKeyPrefix marks the keys issued by the service, so that leaked keys are easy to find by secret scanners.
+const apiKeyPrefix = "usk"
+
+type APIKeySvc struct {
+	storage ports.APIKeyStorage
+}
+
+var _ ports.APIKeyService = (*APIKeySvc)(nil)
+
+// NewAPIKeySvc returns a new instance of APIKeySvc.
+func NewAPIKeySvc(storage ports.APIKeyStorage) *APIKeySvc {
+	return &APIKeySvc{
+		storage: storage,
+	}
+}
+
+// CreateAPIKey mints a new key in the form "usk_<prefix>_<secret>". The prefix identifies the key in storage,
+// only the hash of the whole key is stored, so the key is returned to the caller once.
+func (ks *APIKeySvc) CreateAPIKey(ctx context.Context, createdBy string, req models.CreateAPIKeyRequest) (models.CreateAPIKeyResponse, error) {
+	if req.Name == "" || len(req.Scopes) == 0 {
+		return models.CreateAPIKeyResponse{}, models.ErrBadRequest
+	}
+	for _, scope := range req.Scopes {
+		if !isKnownScope(scope) {
+			return models.CreateAPIKeyResponse{}, models.ErrInvalidScope
+		}
+	}
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		return models.CreateAPIKeyResponse{}, models.ErrBadRequest
+	}
+
+	prefix, err := randomString(4, hex.EncodeToString)
+	if err != nil {
+		return models.CreateAPIKeyResponse{}, err
+	}
+	secret, err := randomString(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return models.CreateAPIKeyResponse{}, err
+	}
+	key := apiKeyPrefix + "_" + prefix + "_" + secret
+
+	apiKey := models.APIKey{
+		Name:      req.Name,
+		Prefix:    prefix,
+		Scopes:    req.Scopes,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+		ExpiresAt: req.ExpiresAt,
+		KeyHash:   sha256Hex(key),
+	}
+	apiKey.ID, err = ks.storage.SaveAPIKey(ctx, apiKey)
+	if err != nil {
+		return models.CreateAPIKeyResponse{}, err
+	}
+	return models.CreateAPIKeyResponse{APIKey: apiKey, Key: key}, nil
+}
+
+func (ks *APIKeySvc) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return ks.storage.ListAPIKeys(ctx)
+}
+
+func (ks *APIKeySvc) RevokeAPIKey(ctx context.Context, id int64) error {
+	found, err := ks.storage.RevokeAPIKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return models.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// Authenticate checks the key and returns the principal with the key's scopes. The key usage time is recorded.
+func (ks *APIKeySvc) Authenticate(ctx context.Context, key string) (models.Principal, error) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyPrefix {
+		return models.Principal{}, models.ErrUnauthorized
+	}
+	apiKey, err := ks.storage.GetAPIKeyByPrefix(ctx, parts[1])
+	if err != nil {
+		return models.Principal{}, models.ErrUnauthorized
+	}
+	if subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(sha256Hex(key))) != 1 {
+		return models.Principal{}, models.ErrUnauthorized
+	}
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && apiKey.ExpiresAt.Before(time.Now())) {
+		return models.Principal{}, models.ErrUnauthorized
+	}
+
+	if err = ks.storage.TouchAPIKey(ctx, apiKey.ID); err != nil {
+		logger.FromContext(ctx).Warn("failed to record api key usage", "id", apiKey.ID, "desc", err.Error())
+	}
+	return models.Principal{
+		Username: "api-key:" + apiKey.Name,
+		APIKeyID: apiKey.ID,
+		Scopes:   apiKey.Scopes,
+	}, nil
+}
+
+func isKnownScope(scope string) bool {
+	for _, s := range models.APIKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
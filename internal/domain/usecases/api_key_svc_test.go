@@ -0,0 +1,159 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"user-service/internal/domain/models"
+	"user-service/internal/ports"
+)
+
+// apiKeyStorage keeps the API keys in memory, by prefix, and records their usage.
+type apiKeyStorage struct {
+	ports.APIKeyStorage
+	keys     map[string]models.APIKey
+	touched  []int64
+	touchErr error
+}
+
+func (s *apiKeyStorage) SaveAPIKey(_ context.Context, key models.APIKey) (int64, error) {
+	key.ID = int64(len(s.keys) + 1)
+	s.keys[key.Prefix] = key
+	return key.ID, nil
+}
+
+func (s *apiKeyStorage) GetAPIKeyByPrefix(_ context.Context, prefix string) (models.APIKey, error) {
+	key, ok := s.keys[prefix]
+	if !ok {
+		return models.APIKey{}, errors.New("no rows")
+	}
+	return key, nil
+}
+
+func (s *apiKeyStorage) TouchAPIKey(_ context.Context, id int64) error {
+	s.touched = append(s.touched, id)
+	return s.touchErr
+}
+
+// createAPIKey creates a key with the scopes and returns it with the stored key.
+func createAPIKey(t *testing.T, svc *APIKeySvc, storage *apiKeyStorage, scopes ...string) (string, *models.APIKey) {
+	t.Helper()
+	resp, err := svc.CreateAPIKey(context.Background(), "admin", models.CreateAPIKeyRequest{Name: "crm", Scopes: scopes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := storage.keys[resp.Prefix]
+	return resp.Key, &stored
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	storage := &apiKeyStorage{keys: map[string]models.APIKey{}}
+	svc := NewAPIKeySvc(storage)
+	key, stored := createAPIKey(t, svc, storage, models.ScopeUsersRead)
+
+	if !regexp.MustCompile(`^usk_[0-9a-f]{8}_[A-Za-z0-9_-]{43}$`).MatchString(key) {
+		t.Errorf("key = %q, want usk_<prefix>_<secret>", key)
+	}
+	if !strings.HasPrefix(key, "usk_"+stored.Prefix+"_") {
+		t.Errorf("key %q does not start with its stored prefix %q", key, stored.Prefix)
+	}
+	if stored.KeyHash != sha256Hex(key) || strings.Contains(stored.KeyHash, key[len("usk_"+stored.Prefix+"_"):]) {
+		t.Errorf("stored hash = %q, want the SHA-256 of the key only", stored.KeyHash)
+	}
+
+	past := time.Now().Add(-time.Minute)
+	for _, req := range []models.CreateAPIKeyRequest{
+		{Scopes: []string{models.ScopeUsersRead}},
+		{Name: "crm"},
+		{Name: "crm", Scopes: []string{models.ScopeUsersRead}, ExpiresAt: &past},
+	} {
+		if _, err := svc.CreateAPIKey(context.Background(), "admin", req); !errors.Is(err, models.ErrBadRequest) {
+			t.Errorf("CreateAPIKey(%+v) = %v, want %v", req, err, models.ErrBadRequest)
+		}
+	}
+	req := models.CreateAPIKeyRequest{Name: "crm", Scopes: []string{models.ScopeUsersRead, "users:delete"}}
+	if _, err := svc.CreateAPIKey(context.Background(), "admin", req); !errors.Is(err, models.ErrInvalidScope) {
+		t.Errorf("CreateAPIKey(unknown scope) = %v, want %v", err, models.ErrInvalidScope)
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	storage := &apiKeyStorage{keys: map[string]models.APIKey{}}
+	svc := NewAPIKeySvc(storage)
+	ctx := context.Background()
+	key, stored := createAPIKey(t, svc, storage, models.ScopeUsersRead, models.ScopeUsersWrite)
+
+	principal, err := svc.Authenticate(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal.APIKeyID != stored.ID || principal.Username != "api-key:crm" || principal.UserID != "" ||
+		!slices.Equal(principal.Scopes, []string{models.ScopeUsersRead, models.ScopeUsersWrite}) {
+		t.Errorf("Authenticate() = %+v, want the key %d with its scopes", principal, stored.ID)
+	}
+	if !principal.IsAPIKey() || principal.IsAdmin() || !principal.HasScope(models.ScopeUsersWrite) {
+		t.Errorf("principal %+v is not an API key limited to its scopes", principal)
+	}
+	if !slices.Equal(storage.touched, []int64{stored.ID}) {
+		t.Errorf("touched keys %v, want %d", storage.touched, stored.ID)
+	}
+
+	// a failure to record the usage does not reject the key
+	storage.touchErr = errors.New("connection refused")
+	if _, err = svc.Authenticate(ctx, key); err != nil {
+		t.Errorf("Authenticate() with a failing touch = %v", err)
+	}
+}
+
+func TestAuthenticateAPIKeyRejected(t *testing.T) {
+	past, future := time.Now().Add(-time.Second), time.Now().Add(time.Hour)
+	tests := []struct {
+		name   string
+		key    func(key, prefix string) string
+		modify func(*models.APIKey)
+	}{
+		{name: "other scheme", key: func(key, _ string) string { return "gsk" + strings.TrimPrefix(key, "usk") }},
+		{name: "no secret", key: func(_, prefix string) string { return "usk_" + prefix }},
+		{name: "unknown prefix", key: func(key, prefix string) string { return strings.Replace(key, prefix, "00000000", 1) }},
+		{name: "longer secret", key: func(key, _ string) string { return key + "A" }},
+		{name: "secret of another key", key: func(_, prefix string) string { return "usk_" + prefix + "_" + strings.Repeat("A", 43) }},
+		{name: "empty", key: func(string, string) string { return "" }},
+		{name: "revoked", modify: func(k *models.APIKey) { k.RevokedAt = &past }},
+		{name: "expired", modify: func(k *models.APIKey) { k.ExpiresAt = &past }},
+		{name: "revoked before expiry", modify: func(k *models.APIKey) { k.ExpiresAt, k.RevokedAt = &future, &past }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &apiKeyStorage{keys: map[string]models.APIKey{}}
+			svc := NewAPIKeySvc(storage)
+			key, stored := createAPIKey(t, svc, storage, models.ScopeAdmin)
+			if tt.key != nil {
+				key = tt.key(key, stored.Prefix)
+			}
+			if tt.modify != nil {
+				tt.modify(stored)
+				storage.keys[stored.Prefix] = *stored
+			}
+			if principal, err := svc.Authenticate(context.Background(), key); !errors.Is(err, models.ErrUnauthorized) {
+				t.Errorf("Authenticate() = %+v, %v, want %v", principal, err, models.ErrUnauthorized)
+			}
+			if len(storage.touched) != 0 {
+				t.Errorf("rejected key usage recorded: %v", storage.touched)
+			}
+		})
+	}
+
+	// a key not expired yet is accepted
+	storage := &apiKeyStorage{keys: map[string]models.APIKey{}}
+	svc := NewAPIKeySvc(storage)
+	key, stored := createAPIKey(t, svc, storage, models.ScopeAdmin)
+	stored.ExpiresAt = &future
+	storage.keys[stored.Prefix] = *stored
+	if _, err := svc.Authenticate(context.Background(), key); err != nil {
+		t.Errorf("Authenticate(not expired) = %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"user-service/internal/domain/models"
+	"user-service/pkg/jsonschema"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// attributeName restricts attribute names to ones that are safe in query parameters and JSON paths.
+var attributeName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+// ListAttributeDefinitions returns the definitions of the custom user attributes.
+func (us *UserSvc) ListAttributeDefinitions(ctx context.Context) (_ []models.AttributeDefinition, err error) {
+	ctx, span := startSpan(ctx, "UserSvc.ListAttributeDefinitions")
+	defer endSpan(span, &err)
+
+	return us.storage.ListAttributeDefinitions(ctx)
+}
+
+// SetAttributeDefinition creates or replaces the definition of the attribute. The values users already have
+// are checked against a changed schema only when the users are updated.
+func (us *UserSvc) SetAttributeDefinition(ctx context.Context, name string, req models.SetAttributeDefinitionRequest) (_ models.AttributeDefinition, err error) {
+	ctx, span := startSpan(ctx, "UserSvc.SetAttributeDefinition", attribute.String("attribute.name", name))
+	defer endSpan(span, &err)
+
+	if !attributeName.MatchString(name) {
+		return models.AttributeDefinition{}, models.ErrInvalidAttributeName
+	}
+	if _, err := jsonschema.Compile(req.Schema); err != nil {
+		return models.AttributeDefinition{}, fmt.Errorf("%w: %w", models.ErrInvalidAttributeSchema, err)
+	}
+	return us.storage.SaveAttributeDefinition(ctx, models.AttributeDefinition{
+		Name:        name,
+		Description: req.Description,
+		Schema:      req.Schema,
+	})
+}
+
+// DeleteAttributeDefinition removes the definition of the attribute and the attribute from all users.
+func (us *UserSvc) DeleteAttributeDefinition(ctx context.Context, name string) (err error) {
+	ctx, span := startSpan(ctx, "UserSvc.DeleteAttributeDefinition", attribute.String("attribute.name", name))
+	defer endSpan(span, &err)
+
+	found, err := us.storage.DeleteAttributeDefinition(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return models.ErrAttributeNotFound
+	}
+	return nil
+}
+
+// validateAttributes checks that every attribute is defined and its value matches the schema of the definition.
+func (us *UserSvc) validateAttributes(ctx context.Context, attrs map[string]any) error {
+	if len(attrs) == 0 {
+		return nil
+	}
+	defs, err := us.storage.ListAttributeDefinitions(ctx)
+	if err != nil {
+		return err
+	}
+	schemas := make(map[string][]byte, len(defs))
+	for _, def := range defs {
+		schemas[def.Name] = def.Schema
+	}
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		raw, ok := schemas[name]
+		if !ok {
+			return fmt.Errorf("%w: unknown attribute %q", models.ErrInvalidAttributes, name)
+		}
+		schema, err := jsonschema.Compile(raw)
+		if err != nil {
+			return fmt.Errorf("schema of attribute %q: %w", name, err)
+		}
+		if err = schema.Validate(attrs[name]); err != nil {
+			return fmt.Errorf("%w: %s%s", models.ErrInvalidAttributes, name, validationDetail(err))
+		}
+	}
+	return nil
+}
+
+// validationDetail formats the validation error to follow the attribute name.
+func validationDetail(err error) string {
+	if verr, ok := err.(*jsonschema.ValidationError); ok && verr.Path != "" {
+		return verr.Path + ": " + verr.Message
+	}
+	return ": " + err.Error()
+}
@@ -0,0 +1,274 @@
+package usecases
+
+import (
+	"context"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+	"user-service/internal/domain/models"
+	"user-service/internal/ports"
+	"user-service/pkg/infra/logger"
+	"user-service/pkg/token"
+	"user-service/pkg/totp"
+
+	"github.com/google/uuid"
+)
+
+const (
+	purposeAccess = "access"
+	purposeMFA    = "mfa"
+
+	recoveryCodesCount = 10
+	qrCodeSize         = 256
+)
+
+type AuthSvc struct {
+	storage ports.AuthStorage
+	tokens  *token.Issuer
+	opts    AuthOptions
+}
+
+type AuthOptions struct {
+	Secret      string
+	TokenTTL    time.Duration
+	MFATokenTTL time.Duration
+	TOTPIssuer  string
+}
+
+var _ ports.AuthService = (*AuthSvc)(nil)
+
+// NewAuthSvc returns a new instance of AuthSvc.
+func NewAuthSvc(storage ports.AuthStorage, opts AuthOptions) *AuthSvc {
+	return &AuthSvc{
+		storage: storage,
+		tokens:  token.NewIssuer(opts.Secret),
+		opts:    opts,
+	}
+}
+
+// Login checks the user's password. If 2FA is enabled, it returns a short-lived token for the second step
+// instead of an access token.
+func (as *AuthSvc) Login(ctx context.Context, req models.LoginRequest) (models.LoginResponse, error) {
+	cred, err := as.storage.GetCredentials(ctx, req.Username)
+	if err != nil || !checkPassword(cred.PasswordHash, req.Password) {
+		return models.LoginResponse{}, models.ErrInvalidCredentials
+	}
+	if cred.Status != models.UserStatusActive {
+		return models.LoginResponse{}, models.ErrUserNotActive
+	}
+	if cred.TOTPEnabled {
+		claims := token.Claims{Subject: cred.UserID, Role: cred.Role, Purpose: purposeMFA, AMR: []string{models.AuthMethodPassword}}
+		mfaToken, err := as.tokens.Issue(claims, as.opts.MFATokenTTL)
+		if err != nil {
+			return models.LoginResponse{}, err
+		}
+		return models.LoginResponse{MFARequired: true, MFAToken: mfaToken}, nil
+	}
+	as.recordLogin(ctx, cred.Username)
+	return as.issueAccessToken(cred, models.AuthMethodPassword)
+}
+
+// LoginSecondFactor completes the login of a user with 2FA enabled using a TOTP or recovery code.
+func (as *AuthSvc) LoginSecondFactor(ctx context.Context, req models.LoginSecondFactorRequest) (models.LoginResponse, error) {
+	claims, err := as.tokens.Parse(req.MFAToken)
+	if err != nil || claims.Purpose != purposeMFA {
+		return models.LoginResponse{}, models.ErrUnauthorized
+	}
+	cred, err := as.credentialsByID(ctx, claims.Subject)
+	if err != nil || !cred.TOTPEnabled || cred.Status != models.UserStatusActive {
+		return models.LoginResponse{}, models.ErrUnauthorized
+	}
+	if err = as.verifySecondFactor(ctx, cred, req.Code); err != nil {
+		return models.LoginResponse{}, err
+	}
+	as.recordLogin(ctx, cred.Username)
+	return as.issueAccessToken(cred, models.AuthMethodPassword, models.AuthMethodOTP)
+}
+
+// Authenticate verifies the access token and returns the caller it was issued to. Tokens of users that
+// are deleted or no longer active are rejected before they expire. The token subject is the user ID, so
+// a token keeps its user after a rename and never passes to another user registering the former
+// username. The username and role are taken from the stored credentials, so a role change applies to
+// the tokens already issued.
+func (as *AuthSvc) Authenticate(ctx context.Context, accessToken string) (models.Principal, error) {
+	claims, err := as.tokens.Parse(accessToken)
+	if err != nil || claims.Purpose != purposeAccess {
+		return models.Principal{}, models.ErrUnauthorized
+	}
+	cred, err := as.credentialsByID(ctx, claims.Subject)
+	if err != nil || cred.Status != models.UserStatusActive {
+		return models.Principal{}, models.ErrUnauthorized
+	}
+	return models.Principal{UserID: cred.UserID, Username: cred.Username, Role: cred.Role, AMR: claims.AMR}, nil
+}
+
+// AuthenticatePassword checks the password and, if 2FA is enabled for the user, the TOTP or recovery code
+// in a single step. It is used by login forms that cannot perform the two-step token exchange.
+func (as *AuthSvc) AuthenticatePassword(ctx context.Context, username, password, code string) (models.Principal, error) {
+	cred, err := as.storage.GetCredentials(ctx, username)
+	if err != nil || !checkPassword(cred.PasswordHash, password) {
+		return models.Principal{}, models.ErrInvalidCredentials
+	}
+	if cred.Status != models.UserStatusActive {
+		return models.Principal{}, models.ErrUserNotActive
+	}
+	amr := []string{models.AuthMethodPassword}
+	if cred.TOTPEnabled {
+		if err = as.verifySecondFactor(ctx, cred, code); err != nil {
+			return models.Principal{}, err
+		}
+		amr = append(amr, models.AuthMethodOTP)
+	}
+	as.recordLogin(ctx, cred.Username)
+	return models.Principal{UserID: cred.UserID, Username: cred.Username, Role: cred.Role, AMR: amr}, nil
+}
+
+// EnrollTOTP generates a new TOTP secret for the user. 2FA is not enabled until the secret is confirmed.
+func (as *AuthSvc) EnrollTOTP(ctx context.Context, username string) (models.TOTPEnrollResponse, error) {
+	cred, err := as.storage.GetCredentials(ctx, username)
+	if err != nil {
+		return models.TOTPEnrollResponse{}, models.ErrUserNotFound
+	}
+	if cred.TOTPEnabled {
+		return models.TOTPEnrollResponse{}, models.ErrTOTPAlreadyEnabled
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return models.TOTPEnrollResponse{}, err
+	}
+	if err = as.storage.SaveTOTPSecret(ctx, username, secret); err != nil {
+		return models.TOTPEnrollResponse{}, err
+	}
+	uri := totp.URI(as.opts.TOTPIssuer, username, secret)
+	qr, err := totp.QRCode(uri, qrCodeSize)
+	if err != nil {
+		return models.TOTPEnrollResponse{}, err
+	}
+	return models.TOTPEnrollResponse{Secret: secret, URI: uri, QRCode: qr}, nil
+}
+
+// ConfirmTOTP enables 2FA once the user proves possession of the enrolled secret, and returns
+// single-use recovery codes. Only their hashes are stored.
+func (as *AuthSvc) ConfirmTOTP(ctx context.Context, username, code string) (models.TOTPConfirmResponse, error) {
+	cred, err := as.storage.GetCredentials(ctx, username)
+	if err != nil {
+		return models.TOTPConfirmResponse{}, models.ErrUserNotFound
+	}
+	switch {
+	case cred.TOTPEnabled:
+		return models.TOTPConfirmResponse{}, models.ErrTOTPAlreadyEnabled
+	case cred.TOTPSecret == "":
+		return models.TOTPConfirmResponse{}, models.ErrTOTPNotEnrolled
+	}
+	// the confirmation code cannot be used to log in afterwards
+	if err = as.useTOTPCode(ctx, cred, code); err != nil {
+		return models.TOTPConfirmResponse{}, err
+	}
+
+	codes := make([]string, recoveryCodesCount)
+	hashes := make([]string, recoveryCodesCount)
+	for i := range codes {
+		codes[i], err = generateRecoveryCode()
+		if err != nil {
+			return models.TOTPConfirmResponse{}, err
+		}
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	if err = as.storage.EnableTOTP(ctx, username, hashes); err != nil {
+		return models.TOTPConfirmResponse{}, err
+	}
+	return models.TOTPConfirmResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTOTP turns off 2FA after checking a TOTP or recovery code.
+func (as *AuthSvc) DisableTOTP(ctx context.Context, username, code string) error {
+	cred, err := as.storage.GetCredentials(ctx, username)
+	if err != nil {
+		return models.ErrUserNotFound
+	}
+	if !cred.TOTPEnabled {
+		return models.ErrTOTPNotEnrolled
+	}
+	if err = as.verifySecondFactor(ctx, cred, code); err != nil {
+		return err
+	}
+	return as.storage.DisableTOTP(ctx, username)
+}
+
+func (as *AuthSvc) verifySecondFactor(ctx context.Context, cred models.Credentials, code string) error {
+	if err := as.useTOTPCode(ctx, cred, code); !errors.Is(err, models.ErrInvalidOTP) {
+		return err
+	}
+	used, err := as.storage.UseRecoveryCode(ctx, cred.Username, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return models.ErrInvalidOTP
+	}
+	return nil
+}
+
+// useTOTPCode accepts the TOTP code once: codes of the time step of the last accepted code, or earlier
+// ones, are rejected as replayed.
+func (as *AuthSvc) useTOTPCode(ctx context.Context, cred models.Credentials, code string) error {
+	step, ok := totp.Validate(cred.TOTPSecret, code, time.Now())
+	if !ok {
+		return models.ErrInvalidOTP
+	}
+	used, err := as.storage.UseTOTPStep(ctx, cred.Username, step)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return models.ErrInvalidOTP
+	}
+	return nil
+}
+
+// recordLogin updates the time of the last login. A failure does not prevent the login.
+func (as *AuthSvc) recordLogin(ctx context.Context, username string) {
+	if err := as.storage.RecordLogin(ctx, username); err != nil {
+		logger.FromContext(ctx).Warn("failed to record login", "desc", err.Error())
+	}
+}
+
+// credentialsByID returns the credentials of the user with the ID taken from a token subject.
+func (as *AuthSvc) credentialsByID(ctx context.Context, id string) (models.Credentials, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return models.Credentials{}, models.ErrUserNotFound
+	}
+	return as.storage.GetCredentialsByID(ctx, parsed.String())
+}
+
+func (as *AuthSvc) issueAccessToken(cred models.Credentials, amr ...string) (models.LoginResponse, error) {
+	claims := token.Claims{Subject: cred.UserID, Role: cred.Role, Purpose: purposeAccess, AMR: amr}
+	accessToken, err := as.tokens.Issue(claims, as.opts.TokenTTL)
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+	return models.LoginResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(as.opts.TokenTTL.Seconds()),
+		Principal:   models.Principal{UserID: cred.UserID, Username: cred.Username, Role: cred.Role, AMR: amr},
+	}, nil
+}
+
+// generateRecoveryCode returns a random code in the form "xxxxx-xxxxx".
+func generateRecoveryCode() (string, error) {
+	s, err := randomString(7, base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString)
+	if err != nil {
+		return "", err
+	}
+	s = strings.ToLower(s[:10])
+	return s[:5] + "-" + s[5:], nil
+}
+
+// hashRecoveryCode normalizes the code the way users are likely to type it and hashes it.
+// Recovery codes are random, so a fast hash is sufficient.
+func hashRecoveryCode(code string) string {
+	return sha256Hex(strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code)))
+}
@@ -0,0 +1,173 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"user-service/internal/domain/models"
+	"user-service/internal/ports"
+	"user-service/pkg/token"
+	"user-service/pkg/totp"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testPassword = "qwerty1234"
+
+// authStorage keeps the credentials of the users in memory, by user ID.
+type authStorage struct {
+	ports.AuthStorage
+	users map[string]models.Credentials
+	steps map[string]int64 // last accepted TOTP step by username
+}
+
+func newAuthStorage(t *testing.T, users ...models.Credentials) *authStorage {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &authStorage{users: map[string]models.Credentials{}, steps: map[string]int64{}}
+	for _, cred := range users {
+		cred.PasswordHash = string(hash)
+		s.users[cred.UserID] = cred
+	}
+	return s
+}
+
+func (s *authStorage) GetCredentials(_ context.Context, username string) (models.Credentials, error) {
+	for _, cred := range s.users {
+		if cred.Username == username {
+			return cred, nil
+		}
+	}
+	return models.Credentials{}, errors.New("no rows")
+}
+
+func (s *authStorage) GetCredentialsByID(_ context.Context, id string) (models.Credentials, error) {
+	cred, ok := s.users[id]
+	if !ok {
+		return models.Credentials{}, errors.New("no rows")
+	}
+	return cred, nil
+}
+
+func (s *authStorage) RecordLogin(context.Context, string) error {
+	return nil
+}
+
+func (s *authStorage) UseTOTPStep(_ context.Context, username string, step int64) (bool, error) {
+	if last, ok := s.steps[username]; ok && last >= step {
+		return false, nil
+	}
+	s.steps[username] = step
+	return true, nil
+}
+
+func (s *authStorage) UseRecoveryCode(context.Context, string, string) (bool, error) {
+	return false, nil
+}
+
+func (s *authStorage) rename(id, username string) {
+	cred := s.users[id]
+	cred.Username = username
+	s.users[id] = cred
+}
+
+func newTestAuthSvc(storage ports.AuthStorage) *AuthSvc {
+	return NewAuthSvc(storage, AuthOptions{Secret: "secret", TokenTTL: time.Hour, MFATokenTTL: time.Minute, TOTPIssuer: "test"})
+}
+
+func TestAccessTokenFollowsUserID(t *testing.T) {
+	const (
+		ivanID = "018f3b6e-6c1a-7d2e-9a4b-3c5d7e9f1a2b"
+		petrID = "018f3b6e-6c1a-7d2e-9a4b-3c5d7e9f1a2c"
+	)
+	storage := newAuthStorage(t, models.Credentials{UserID: ivanID, Username: "IvanIvanov2000", Role: models.RoleUser, Status: models.UserStatusActive})
+	svc := newTestAuthSvc(storage)
+	ctx := context.Background()
+
+	resp, err := svc.Login(ctx, models.LoginRequest{Username: "IvanIvanov2000", Password: testPassword})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := svc.tokens.Parse(resp.AccessToken)
+	if err != nil || claims.Subject != ivanID {
+		t.Fatalf("token subject = %q (%v), want the user ID %s", claims.Subject, err, ivanID)
+	}
+
+	// the former username is taken by another user while the token is still valid
+	storage.rename(ivanID, "IvanIvanov2001")
+	storage.users[petrID] = models.Credentials{UserID: petrID, Username: "IvanIvanov2000", Role: models.RoleAdmin, Status: models.UserStatusActive}
+	principal, err := svc.Authenticate(ctx, resp.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal.Username != "IvanIvanov2001" || principal.Role != models.RoleUser {
+		t.Errorf("token authenticates as %s (%s), want IvanIvanov2001 (user)", principal.Username, principal.Role)
+	}
+
+	// tokens naming the user by username are not accepted
+	legacy, err := svc.tokens.Issue(token.Claims{Subject: "IvanIvanov2000", Role: models.RoleUser, Purpose: purposeAccess}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = svc.Authenticate(ctx, legacy); !errors.Is(err, models.ErrUnauthorized) {
+		t.Errorf("Authenticate(username subject) = %v, want %v", err, models.ErrUnauthorized)
+	}
+}
+
+func TestMFATokenSubjectIsUserID(t *testing.T) {
+	const id = "018f3b6e-6c1a-7d2e-9a4b-3c5d7e9f1a2b"
+	storage := newAuthStorage(t, models.Credentials{
+		UserID: id, Username: "IvanIvanov2000", Role: models.RoleUser, Status: models.UserStatusActive, TOTPEnabled: true,
+	})
+	svc := newTestAuthSvc(storage)
+	resp, err := svc.Login(context.Background(), models.LoginRequest{Username: "IvanIvanov2000", Password: testPassword})
+	if err != nil || !resp.MFARequired {
+		t.Fatalf("Login() = %+v, %v, want the second factor required", resp, err)
+	}
+	claims, err := svc.tokens.Parse(resp.MFAToken)
+	if err != nil || claims.Subject != id || claims.Purpose != purposeMFA {
+		t.Errorf("mfa token claims = %+v (%v), want the subject %s", claims, err, id)
+	}
+}
+
+func TestTOTPCodeIsUsedOnce(t *testing.T) {
+	const id = "018f3b6e-6c1a-7d2e-9a4b-3c5d7e9f1a2b"
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage := newAuthStorage(t, models.Credentials{
+		UserID: id, Username: "IvanIvanov2000", Role: models.RoleUser, Status: models.UserStatusActive,
+		TOTPSecret: secret, TOTPEnabled: true,
+	})
+	svc := newTestAuthSvc(storage)
+	ctx := context.Background()
+
+	code, err := totp.Code(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = svc.AuthenticatePassword(ctx, "IvanIvanov2000", testPassword, code); err != nil {
+		t.Fatalf("AuthenticatePassword() = %v", err)
+	}
+	if _, err = svc.AuthenticatePassword(ctx, "IvanIvanov2000", testPassword, code); !errors.Is(err, models.ErrInvalidOTP) {
+		t.Errorf("AuthenticatePassword(replayed code) = %v, want %v", err, models.ErrInvalidOTP)
+	}
+
+	// the code of the previous step is still within the skew window, but it is older than the accepted one
+	previous, err := totp.Code(secret, time.Now().Add(-totp.Period))
+	if err != nil {
+		t.Fatal(err)
+	}
+	login, err := svc.Login(ctx, models.LoginRequest{Username: "IvanIvanov2000", Password: testPassword})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = svc.LoginSecondFactor(ctx, models.LoginSecondFactorRequest{MFAToken: login.MFAToken, Code: previous}); !errors.Is(err, models.ErrInvalidOTP) {
+		t.Errorf("LoginSecondFactor(earlier code) = %v, want %v", err, models.ErrInvalidOTP)
+	}
+}
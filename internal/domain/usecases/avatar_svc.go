@@ -0,0 +1,178 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"user-service/internal/domain/models"
+	"user-service/internal/ports"
+	"user-service/pkg/imaging"
+	"user-service/pkg/infra/logger"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// avatarPrefix is the blob storage prefix of the avatars; the thumbnails are kept under
+// avatars/<user id>/<version>/<size>.<extension>.
+const avatarPrefix = "avatars"
+
+type AvatarSvc struct {
+	storage ports.AvatarStorage
+	blobs   ports.BlobStorage
+	opts    AvatarOptions
+}
+
+type AvatarOptions struct {
+	MaxPixels int // larger images are rejected before decoding
+}
+
+var _ ports.AvatarService = (*AvatarSvc)(nil)
+
+// NewAvatarSvc returns a new instance of AvatarSvc.
+func NewAvatarSvc(storage ports.AvatarStorage, blobs ports.BlobStorage, opts AvatarOptions) *AvatarSvc {
+	return &AvatarSvc{
+		storage: storage,
+		blobs:   blobs,
+		opts:    opts,
+	}
+}
+
+// SetAvatar makes the thumbnails of the image, stores them and replaces the user's avatar with them.
+// The thumbnails are encoded anew, so that the metadata of the uploaded file, such as EXIF, is not published.
+func (as *AvatarSvc) SetAvatar(ctx context.Context, username string, data []byte) (_ models.Avatar, err error) {
+	ctx, span := startSpan(ctx, "AvatarSvc.SetAvatar", attribute.String("user.username", username))
+	defer endSpan(span, &err)
+
+	if err = authorizeUserChange(ctx, username); err != nil {
+		return models.Avatar{}, err
+	}
+	id, current, found, err := as.storage.GetAvatar(ctx, username)
+	if err != nil {
+		return models.Avatar{}, err
+	}
+	if !found {
+		return models.Avatar{}, models.ErrUserNotFound
+	}
+	img, format, err := imaging.Decode(data, as.opts.MaxPixels)
+	switch {
+	case errors.Is(err, imaging.ErrTooLarge):
+		return models.Avatar{}, models.ErrAvatarTooLarge
+	case err != nil:
+		return models.Avatar{}, models.ErrUnsupportedAvatar
+	}
+
+	// the same image gets the same version, so that repeated uploads keep the cached URL
+	sum := sha256.Sum256(data)
+	version := hex.EncodeToString(sum[:8])
+	format = imaging.OutputFormat(format)
+	thumb := img
+	for _, size := range models.AvatarSizes {
+		// each thumbnail is scaled from the previous, larger one
+		thumb = imaging.Thumbnail(thumb, size)
+		var buf bytes.Buffer
+		if err = imaging.Encode(&buf, thumb, format); err != nil {
+			return models.Avatar{}, err
+		}
+		if err = as.blobs.PutBlob(ctx, avatarKey(id, version, size, format), buf.Bytes()); err != nil {
+			return models.Avatar{}, err
+		}
+	}
+
+	avatar := version + "." + format
+	previous, found, err := as.storage.SetAvatar(ctx, username, avatar)
+	if err != nil || !found {
+		if avatar != current {
+			as.deleteVersion(ctx, id, version)
+		}
+		if err != nil {
+			return models.Avatar{}, err
+		}
+		return models.Avatar{}, models.ErrUserNotFound
+	}
+	if previous != "" && previous != avatar {
+		previousVersion, _, _ := strings.Cut(previous, ".")
+		as.deleteVersion(ctx, id, previousVersion)
+	}
+	return models.Avatar{URL: models.AvatarURL(id, avatar), Sizes: models.AvatarSizes}, nil
+}
+
+// GetAvatar opens the thumbnail of the user's avatar of the size, models.AvatarDefaultSize if zero.
+func (as *AvatarSvc) GetAvatar(ctx context.Context, username string, size int) (_ models.AvatarImage, err error) {
+	ctx, span := startSpan(ctx, "AvatarSvc.GetAvatar", attribute.String("user.username", username))
+	defer endSpan(span, &err)
+
+	if size == 0 {
+		size = models.AvatarDefaultSize
+	}
+	if !slices.Contains(models.AvatarSizes, size) {
+		return models.AvatarImage{}, models.ErrInvalidAvatarSize
+	}
+	id, avatar, found, err := as.storage.GetAvatar(ctx, username)
+	if err != nil {
+		return models.AvatarImage{}, err
+	}
+	if !found {
+		return models.AvatarImage{}, models.ErrUserNotFound
+	}
+	if avatar == "" {
+		return models.AvatarImage{}, models.ErrAvatarNotFound
+	}
+	version, format, _ := strings.Cut(avatar, ".")
+	blob, found, err := as.blobs.GetBlob(ctx, avatarKey(id, version, size, format))
+	if err != nil {
+		return models.AvatarImage{}, err
+	}
+	if !found {
+		return models.AvatarImage{}, models.ErrAvatarNotFound
+	}
+	return models.AvatarImage{Blob: blob, ContentType: "image/" + format, Version: version}, nil
+}
+
+// PurgeAvatars deletes the avatars of the users that no longer exist, i.e. have been purged.
+func (as *AvatarSvc) PurgeAvatars(ctx context.Context) (n int64, err error) {
+	ctx, span := startSpan(ctx, "AvatarSvc.PurgeAvatars")
+	defer endSpan(span, &err)
+
+	names, err := as.blobs.ListBlobs(ctx, avatarPrefix)
+	if err != nil {
+		return 0, err
+	}
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		if _, err := uuid.Parse(name); err == nil {
+			ids = append(ids, name)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	missing, err := as.storage.GetMissingUserIDs(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range missing {
+		if err = as.blobs.DeleteBlobs(ctx, avatarPrefix+"/"+id); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// deleteVersion deletes the thumbnails of the version of the user's avatar. Failures leave unused files
+// only, so they are logged.
+func (as *AvatarSvc) deleteVersion(ctx context.Context, userID, version string) {
+	if err := as.blobs.DeleteBlobs(ctx, avatarPrefix+"/"+userID+"/"+version); err != nil {
+		logger.FromContext(ctx).Warn("deleting avatar failed", "user_id", userID, "version", version, "desc", err.Error())
+	}
+}
+
+func avatarKey(userID, version string, size int, format string) string {
+	return fmt.Sprintf("%s/%s/%s/%d.%s", avatarPrefix, userID, version, size, format)
+}
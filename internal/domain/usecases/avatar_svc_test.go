@@ -0,0 +1,186 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"sort"
+	"strings"
+	"testing"
+	"user-service/internal/domain/models"
+)
+
+// avatarStorage keeps the avatar of a single user in memory.
+type avatarStorage struct {
+	userID   string
+	username string
+	avatar   string
+}
+
+func (s *avatarStorage) GetAvatar(_ context.Context, username string) (string, string, bool, error) {
+	return s.userID, s.avatar, username == s.username, nil
+}
+
+func (s *avatarStorage) SetAvatar(_ context.Context, username, avatar string) (string, bool, error) {
+	if username != s.username {
+		return "", false, nil
+	}
+	previous := s.avatar
+	s.avatar = avatar
+	return previous, true, nil
+}
+
+func (s *avatarStorage) GetMissingUserIDs(context.Context, []string) ([]string, error) {
+	return nil, nil
+}
+
+// blobStorage keeps the blobs in memory.
+type blobStorage map[string][]byte
+
+func (b blobStorage) PutBlob(_ context.Context, key string, data []byte) error {
+	b[key] = data
+	return nil
+}
+
+func (b blobStorage) GetBlob(context.Context, string) (models.Blob, bool, error) {
+	return models.Blob{}, false, errors.New("not used by SetAvatar")
+}
+
+func (b blobStorage) ListBlobs(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for key := range b {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (b blobStorage) DeleteBlobs(_ context.Context, prefix string) error {
+	for key := range b {
+		if strings.HasPrefix(key, prefix) {
+			delete(b, key)
+		}
+	}
+	return nil
+}
+
+// keys returns the sorted keys of the blobs.
+func (b blobStorage) keys() []string {
+	keys, _ := b.ListBlobs(context.Background(), "")
+	sort.Strings(keys)
+	return keys
+}
+
+// testImage returns a w×h image of the colour in the format.
+func testImage(t *testing.T, format string, w, h int, c color.RGBA) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	var buf bytes.Buffer
+	var err error
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, img, nil)
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSetAvatar(t *testing.T) {
+	const id = "018f3b6e-6c1a-7d2e-9a4b-3c5d7e9f1a2b"
+	red := color.RGBA{R: 255, A: 255}
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		data       []byte
+		wantFormat string
+		wantSizes  []int // of the stored thumbnails, largest first
+		wantErr    error
+	}{
+		{name: "png", ctx: asUser(testUsername), data: testImage(t, "png", 900, 600, red), wantFormat: "png", wantSizes: []int{512, 256, 128, 64}},
+		{name: "jpeg stays jpeg", ctx: asUser(testUsername), data: testImage(t, "jpeg", 300, 900, red), wantFormat: "jpeg", wantSizes: []int{300, 256, 128, 64}},
+		{name: "small image", ctx: asUser(testUsername), data: testImage(t, "png", 100, 100, red), wantFormat: "png", wantSizes: []int{100, 100, 100, 64}},
+		{name: "administrator", ctx: models.WithPrincipal(context.Background(), models.Principal{Username: "admin", Role: models.RoleAdmin}), data: testImage(t, "png", 64, 64, red), wantFormat: "png", wantSizes: []int{64, 64, 64, 64}},
+		{name: "too many pixels", ctx: asUser(testUsername), data: testImage(t, "png", 2000, 1000, red), wantErr: models.ErrAvatarTooLarge},
+		{name: "not an image", ctx: asUser(testUsername), data: []byte("%PDF-1.4"), wantErr: models.ErrUnsupportedAvatar},
+		{name: "another user", ctx: asUser("PetrPetrov1990"), data: testImage(t, "png", 64, 64, red), wantErr: models.ErrForbidden},
+		{name: "anonymous", ctx: context.Background(), data: testImage(t, "png", 64, 64, red), wantErr: models.ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &avatarStorage{userID: id, username: testUsername}
+			blobs := blobStorage{}
+			svc := NewAvatarSvc(storage, blobs, AvatarOptions{MaxPixels: 1 << 20})
+			avatar, err := svc.SetAvatar(tt.ctx, testUsername, tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetAvatar() = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if storage.avatar != "" || len(blobs) > 0 {
+					t.Errorf("stored on error: avatar %q, blobs %v", storage.avatar, blobs.keys())
+				}
+				return
+			}
+
+			version, format, _ := strings.Cut(storage.avatar, ".")
+			if format != tt.wantFormat || avatar.URL != models.AvatarURL(id, storage.avatar) {
+				t.Errorf("avatar %q, URL %s", storage.avatar, avatar.URL)
+			}
+			for i, size := range models.AvatarSizes {
+				key := fmt.Sprintf("avatars/%s/%s/%d.%s", id, version, size, format)
+				data, ok := blobs[key]
+				if !ok {
+					t.Fatalf("no thumbnail %s among %v", key, blobs.keys())
+				}
+				cfg, got, err := image.DecodeConfig(bytes.NewReader(data))
+				if err != nil || got != tt.wantFormat || cfg.Width != tt.wantSizes[i] || cfg.Height != tt.wantSizes[i] {
+					t.Errorf("thumbnail %d: %s %dx%d (%v), want %s %dx%d", size, got, cfg.Width, cfg.Height, err, tt.wantFormat, tt.wantSizes[i], tt.wantSizes[i])
+				}
+			}
+			if len(blobs) != len(models.AvatarSizes) {
+				t.Errorf("blobs = %v, want the thumbnails only", blobs.keys())
+			}
+		})
+	}
+}
+
+func TestSetAvatarReplacesPreviousVersion(t *testing.T) {
+	const id = "018f3b6e-6c1a-7d2e-9a4b-3c5d7e9f1a2b"
+	storage := &avatarStorage{userID: id, username: testUsername}
+	blobs := blobStorage{}
+	svc := NewAvatarSvc(storage, blobs, AvatarOptions{MaxPixels: 1 << 20})
+	ctx := asUser(testUsername)
+
+	first, err := svc.SetAvatar(ctx, testUsername, testImage(t, "png", 64, 64, color.RGBA{R: 255, A: 255}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := svc.SetAvatar(ctx, testUsername, testImage(t, "png", 64, 64, color.RGBA{R: 255, A: 255}))
+	if err != nil || again.URL != first.URL {
+		t.Fatalf("the same image got the URL %s (%v), want %s", again.URL, err, first.URL)
+	}
+	second, err := svc.SetAvatar(ctx, testUsername, testImage(t, "png", 64, 64, color.RGBA{B: 255, A: 255}))
+	if err != nil || second.URL == first.URL {
+		t.Fatalf("another image got the URL %s (%v)", second.URL, err)
+	}
+	version, _, _ := strings.Cut(storage.avatar, ".")
+	for _, key := range blobs.keys() {
+		if !strings.HasPrefix(key, "avatars/"+id+"/"+version+"/") {
+			t.Errorf("the thumbnail %s of the previous version is kept", key)
+		}
+	}
+	if len(blobs) != len(models.AvatarSizes) {
+		t.Errorf("blobs = %v", blobs.keys())
+	}
+}
@@ -0,0 +1,113 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"user-service/internal/domain/models"
+	"user-service/internal/ports"
+)
+
+type HealthSvc struct {
+	storage      ports.HealthStorage
+	opts         HealthOptions
+	started      atomic.Bool
+	shuttingDown atomic.Bool
+}
+
+type HealthOptions struct {
+	CheckTimeout time.Duration // limit for a single dependency check
+}
+
+var _ ports.HealthService = (*HealthSvc)(nil)
+
+// NewHealthSvc returns a new instance of HealthSvc.
+func NewHealthSvc(storage ports.HealthStorage, opts HealthOptions) *HealthSvc {
+	return &HealthSvc{
+		storage: storage,
+		opts:    opts,
+	}
+}
+
+// Ready checks the dependencies concurrently. The service is ready if all of them are up, the startup
+// is complete and it is not shutting down.
+func (hs *HealthSvc) Ready(ctx context.Context) models.HealthResponse {
+	checks := map[string]func(ctx context.Context) error{
+		"postgres":   hs.storage.Ping,
+		"migrations": hs.checkMigrations,
+	}
+
+	resp := models.HealthResponse{
+		Status:       models.HealthStatusUp,
+		Starting:     !hs.started.Load(),
+		ShuttingDown: hs.shuttingDown.Load(),
+		Checks:       make(map[string]models.HealthCheckResult, len(checks)),
+	}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(ctx context.Context) error) {
+			defer wg.Done()
+			res := hs.runCheck(ctx, check)
+			mu.Lock()
+			resp.Checks[name] = res
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	if resp.Starting || resp.ShuttingDown {
+		resp.Status = models.HealthStatusDown
+	}
+	for _, res := range resp.Checks {
+		if res.Status != models.HealthStatusUp {
+			resp.Status = models.HealthStatusDown
+		}
+	}
+	return resp
+}
+
+// SetStarted marks the startup as complete. Until then the service is not ready.
+func (hs *HealthSvc) SetStarted() {
+	hs.started.Store(true)
+}
+
+// SetShuttingDown makes the service report itself as not ready, so that load balancers stop routing
+// new requests to it before the server is stopped.
+func (hs *HealthSvc) SetShuttingDown() {
+	hs.shuttingDown.Store(true)
+}
+
+func (hs *HealthSvc) runCheck(ctx context.Context, check func(ctx context.Context) error) models.HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, hs.opts.CheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	res := models.HealthCheckResult{
+		Status:    models.HealthStatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		res.Status = models.HealthStatusDown
+		res.Error = err.Error()
+	}
+	return res
+}
+
+func (hs *HealthSvc) checkMigrations(ctx context.Context) error {
+	pending, err := hs.storage.PendingMigrations(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("pending migrations: %s", strings.Join(pending, ", "))
+	}
+	return nil
+}
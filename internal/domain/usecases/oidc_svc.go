@@ -0,0 +1,344 @@
+package usecases
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"time"
+	"user-service/internal/domain/models"
+	"user-service/internal/ports"
+	"user-service/pkg/token"
+)
+
+type OIDCSvc struct {
+	storage ports.OIDCStorage
+	users   ports.UserStorage
+	signer  *token.RSASigner
+	opts    OIDCOptions
+}
+
+type OIDCOptions struct {
+	Issuer   string // external base URL of the service, e.g. "http://localhost:3000"
+	CodeTTL  time.Duration
+	TokenTTL time.Duration
+}
+
+var _ ports.OIDCService = (*OIDCSvc)(nil)
+
+// NewOIDCSvc returns a new instance of OIDCSvc signing ID and access tokens with the given signer.
+func NewOIDCSvc(storage ports.OIDCStorage, users ports.UserStorage, signer *token.RSASigner, opts OIDCOptions) *OIDCSvc {
+	opts.Issuer = strings.TrimRight(opts.Issuer, "/")
+	return &OIDCSvc{
+		storage: storage,
+		users:   users,
+		signer:  signer,
+		opts:    opts,
+	}
+}
+
+func (o *OIDCSvc) Discovery() models.DiscoveryDocument {
+	return models.DiscoveryDocument{
+		Issuer:                            o.opts.Issuer,
+		AuthorizationEndpoint:             o.opts.Issuer + "/oauth2/authorize",
+		TokenEndpoint:                     o.opts.Issuer + "/oauth2/token",
+		UserInfoEndpoint:                  o.opts.Issuer + "/oauth2/userinfo",
+		JWKSURI:                           o.opts.Issuer + "/oauth2/jwks",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{"RS256"},
+		ScopesSupported:                   models.OIDCScopes,
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		ClaimsSupported: []string{
+			"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce",
+			"preferred_username", "name", "given_name", "family_name", "email", "phone_number",
+		},
+	}
+}
+
+func (o *OIDCSvc) JWKS() token.JWKS {
+	return o.signer.JWKS()
+}
+
+// RegisterClient stores a new client. Confidential clients receive a secret, which is returned only once.
+func (o *OIDCSvc) RegisterClient(ctx context.Context, req models.CreateOAuthClientRequest) (models.CreateOAuthClientResponse, error) {
+	if req.Name == "" || len(req.RedirectURIs) == 0 {
+		return models.CreateOAuthClientResponse{}, models.ErrBadRequest
+	}
+	for _, uri := range req.RedirectURIs {
+		u, err := url.Parse(uri)
+		if err != nil || !u.IsAbs() || u.Fragment != "" {
+			return models.CreateOAuthClientResponse{}, models.ErrInvalidRedirectURI
+		}
+	}
+
+	clientID, err := randomString(16, hex.EncodeToString)
+	if err != nil {
+		return models.CreateOAuthClientResponse{}, err
+	}
+	client := models.OAuthClient{
+		ClientID:     clientID,
+		Name:         req.Name,
+		RedirectURIs: req.RedirectURIs,
+		Public:       req.Public,
+		CreatedAt:    time.Now(),
+	}
+	var secret string
+	if !req.Public {
+		secret, err = randomString(32, base64.RawURLEncoding.EncodeToString)
+		if err != nil {
+			return models.CreateOAuthClientResponse{}, err
+		}
+		client.SecretHash = sha256Hex(secret)
+	}
+	if err = o.storage.SaveOAuthClient(ctx, client); err != nil {
+		return models.CreateOAuthClientResponse{}, err
+	}
+	return models.CreateOAuthClientResponse{OAuthClient: client, ClientSecret: secret}, nil
+}
+
+func (o *OIDCSvc) ListClients(ctx context.Context) ([]models.OAuthClient, error) {
+	return o.storage.ListOAuthClients(ctx)
+}
+
+func (o *OIDCSvc) DeleteClient(ctx context.Context, clientID string) error {
+	found, err := o.storage.DeleteOAuthClient(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return models.ErrOAuthClientNotFound
+	}
+	return nil
+}
+
+// ValidateAuthorizeRequest checks the client and the redirect URI. Errors returned from it must be shown
+// to the user instead of redirecting, since the redirect URI can not be trusted.
+func (o *OIDCSvc) ValidateAuthorizeRequest(ctx context.Context, req models.AuthorizeRequest) (models.OAuthClient, error) {
+	client, err := o.storage.GetOAuthClient(ctx, req.ClientID)
+	if err != nil {
+		return models.OAuthClient{}, models.ErrOAuthClientNotFound
+	}
+	for _, uri := range client.RedirectURIs {
+		if uri == req.RedirectURI {
+			return client, nil
+		}
+	}
+	return models.OAuthClient{}, models.ErrInvalidRedirectURI
+}
+
+// Authorize issues an authorization code for the logged-in user and returns the URL to redirect the user to.
+// Protocol errors are reported to the client through the redirect URI as well.
+func (o *OIDCSvc) Authorize(ctx context.Context, req models.AuthorizeRequest, principal models.Principal) (string, error) {
+	client, err := o.ValidateAuthorizeRequest(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if oauthErr := o.checkAuthorizeParams(req, client); oauthErr != nil {
+		return redirectURL(req.RedirectURI, url.Values{
+			"error":             {oauthErr.Code},
+			"error_description": {oauthErr.Description},
+			"state":             {req.State},
+		}), nil
+	}
+
+	code, err := randomString(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	err = o.storage.SaveAuthorizationCode(ctx, models.AuthorizationCode{
+		CodeHash:      sha256Hex(code),
+		ClientID:      client.ClientID,
+		Username:      principal.Username,
+		RedirectURI:   req.RedirectURI,
+		Scope:         normalizeScope(req.Scope),
+		Nonce:         req.Nonce,
+		CodeChallenge: req.CodeChallenge,
+		AuthTime:      now,
+		ExpiresAt:     now.Add(o.opts.CodeTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+	return redirectURL(req.RedirectURI, url.Values{"code": {code}, "state": {req.State}}), nil
+}
+
+// Exchange redeems the authorization code for an ID token and an access token for the userinfo endpoint.
+func (o *OIDCSvc) Exchange(ctx context.Context, req models.TokenRequest) (models.TokenResponse, error) {
+	if req.GrantType != "authorization_code" {
+		return models.TokenResponse{}, models.NewOAuthError("unsupported_grant_type", "only authorization_code is supported")
+	}
+	client, err := o.storage.GetOAuthClient(ctx, req.ClientID)
+	if err != nil {
+		return models.TokenResponse{}, models.NewOAuthError("invalid_client", "unknown client")
+	}
+	if !client.Public && subtle.ConstantTimeCompare([]byte(client.SecretHash), []byte(sha256Hex(req.ClientSecret))) != 1 {
+		return models.TokenResponse{}, models.NewOAuthError("invalid_client", "client authentication failed")
+	}
+
+	code, err := o.storage.ConsumeAuthorizationCode(ctx, sha256Hex(req.Code))
+	if err != nil || code.ExpiresAt.Before(time.Now()) {
+		return models.TokenResponse{}, models.NewOAuthError("invalid_grant", "authorization code is invalid or expired")
+	}
+	if code.ClientID != client.ClientID || code.RedirectURI != req.RedirectURI {
+		return models.TokenResponse{}, models.NewOAuthError("invalid_grant", "authorization code was issued to another client or redirect_uri")
+	}
+	if code.CodeChallenge != "" || req.CodeVerifier != "" {
+		if !verifyPKCE(code.CodeChallenge, req.CodeVerifier) {
+			return models.TokenResponse{}, models.NewOAuthError("invalid_grant", "code_verifier does not match code_challenge")
+		}
+	}
+
+	user, err := o.users.GetUser(ctx, code.Username)
+	if err != nil {
+		return models.TokenResponse{}, models.NewOAuthError("invalid_grant", "user no longer exists")
+	}
+	if user.Status != models.UserStatusActive {
+		return models.TokenResponse{}, models.NewOAuthError("invalid_grant", "user is not active")
+	}
+
+	now := time.Now()
+	idToken, err := o.signer.Sign(models.IDTokenClaims{
+		Issuer:   o.opts.Issuer,
+		Audience: client.ClientID,
+		IssuedAt: now.Unix(),
+		Expires:  now.Add(o.opts.TokenTTL).Unix(),
+		AuthTime: code.AuthTime.Unix(),
+		Nonce:    code.Nonce,
+		UserInfo: userInfo(user, code.Scope),
+	})
+	if err != nil {
+		return models.TokenResponse{}, err
+	}
+	accessToken, err := o.signer.Sign(models.OIDCAccessTokenClaims{
+		Issuer:   o.opts.Issuer,
+		Subject:  user.ID,
+		ClientID: client.ClientID,
+		Scope:    code.Scope,
+		IssuedAt: now.Unix(),
+		Expires:  now.Add(o.opts.TokenTTL).Unix(),
+	})
+	if err != nil {
+		return models.TokenResponse{}, err
+	}
+	return models.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(o.opts.TokenTTL.Seconds()),
+		IDToken:     idToken,
+		Scope:       code.Scope,
+	}, nil
+}
+
+// UserInfo returns the claims about the user the access token was issued for, limited by the granted scopes.
+// Tokens of users that are no longer active are rejected before they expire.
+func (o *OIDCSvc) UserInfo(ctx context.Context, accessToken string) (models.UserInfo, error) {
+	var claims models.OIDCAccessTokenClaims
+	if err := o.signer.Verify(accessToken, &claims); err != nil {
+		return models.UserInfo{}, models.ErrUnauthorized
+	}
+	if claims.Issuer != o.opts.Issuer || claims.ClientID == "" || time.Now().Unix() >= claims.Expires {
+		return models.UserInfo{}, models.ErrUnauthorized
+	}
+	// the subject is the user ID, which is kept when the user is renamed
+	username, err := o.users.GetUsernameByID(ctx, claims.Subject)
+	if err != nil {
+		return models.UserInfo{}, models.ErrUserNotFound
+	}
+	user, err := o.users.GetUser(ctx, username)
+	if err != nil {
+		return models.UserInfo{}, models.ErrUserNotFound
+	}
+	if user.Status != models.UserStatusActive {
+		return models.UserInfo{}, models.ErrUnauthorized
+	}
+	return userInfo(user, claims.Scope), nil
+}
+
+func (o *OIDCSvc) checkAuthorizeParams(req models.AuthorizeRequest, client models.OAuthClient) *models.OAuthError {
+	switch {
+	case req.ResponseType != "code":
+		return models.NewOAuthError("unsupported_response_type", "only response_type=code is supported")
+	case !hasScope(req.Scope, models.ScopeOpenID):
+		return models.NewOAuthError("invalid_scope", "scope must include openid")
+	case req.CodeChallenge == "" && client.Public:
+		return models.NewOAuthError("invalid_request", "code_challenge is required for public clients")
+	case req.CodeChallenge != "" && req.CodeChallengeMethod != "S256":
+		return models.NewOAuthError("invalid_request", "only code_challenge_method=S256 is supported")
+	}
+	for _, scope := range strings.Fields(req.Scope) {
+		if !isOIDCScope(scope) {
+			return models.NewOAuthError("invalid_scope", "unsupported scope "+scope)
+		}
+	}
+	return nil
+}
+
+// userInfo maps the user to the standard claims allowed by the scope. The subject is the user ID: unlike
+// the username, it never changes and is never reassigned (OpenID Connect Core, section 5.7).
+func userInfo(user models.GetUserResponse, scope string) models.UserInfo {
+	info := models.UserInfo{Subject: user.ID}
+	if hasScope(scope, models.ScopeProfile) {
+		info.PreferredUsername = user.Username
+		info.GivenName = user.FirstName
+		info.FamilyName = user.LastName
+		info.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+	}
+	if hasScope(scope, models.ScopeEmail) {
+		info.Email = user.Email
+	}
+	if hasScope(scope, models.ScopePhone) {
+		info.PhoneNumber = user.Phone
+	}
+	return info
+}
+
+// verifyPKCE checks the verifier against the S256 challenge (RFC 7636, section 4.6).
+func verifyPKCE(challenge, verifier string) bool {
+	if challenge == "" || len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func redirectURL(base string, params url.Values) string {
+	u, _ := url.Parse(base)
+	q := u.Query()
+	for k, v := range params {
+		if len(v) > 0 && v[0] != "" {
+			q.Set(k, v[0])
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func hasScope(scope, want string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == want {
+			return true
+		}
+	}
+	return false
+}
+
+func isOIDCScope(scope string) bool {
+	for _, s := range models.OIDCScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeScope(scope string) string {
+	return strings.Join(strings.Fields(scope), " ")
+}
@@ -0,0 +1,103 @@
+package usecases
+
+import (
+	"context"
+	"user-service/internal/domain/models"
+	"user-service/internal/ports"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/text/language"
+)
+
+type PreferencesSvc struct {
+	storage ports.PreferencesStorage
+}
+
+var _ ports.PreferencesService = (*PreferencesSvc)(nil)
+
+// NewPreferencesSvc returns a new instance of PreferencesSvc.
+func NewPreferencesSvc(storage ports.PreferencesStorage) *PreferencesSvc {
+	return &PreferencesSvc{
+		storage: storage,
+	}
+}
+
+// GetPreferences returns the user's preferences: the defaults with the ones the user has set.
+func (ps *PreferencesSvc) GetPreferences(ctx context.Context, username string) (_ models.Preferences, err error) {
+	ctx, span := startSpan(ctx, "PreferencesSvc.GetPreferences", attribute.String("user.username", username))
+	defer endSpan(span, &err)
+
+	if err = authorizeUserChange(ctx, username); err != nil {
+		return models.Preferences{}, err
+	}
+	prefs, found, err := ps.storage.GetPreferences(ctx, username)
+	if err != nil {
+		return models.Preferences{}, err
+	}
+	if !found {
+		return models.Preferences{}, models.ErrUserNotFound
+	}
+	return prefs.Apply(models.DefaultPreferences()), nil
+}
+
+// SetPreferences replaces the preferences the user has set. The ones not given revert to the defaults.
+func (ps *PreferencesSvc) SetPreferences(ctx context.Context, username string, prefs models.PreferencesPatch) (_ models.Preferences, err error) {
+	ctx, span := startSpan(ctx, "PreferencesSvc.SetPreferences", attribute.String("user.username", username))
+	defer endSpan(span, &err)
+
+	if err = authorizeUserChange(ctx, username); err != nil {
+		return models.Preferences{}, err
+	}
+	if prefs, err = normalizePreferences(prefs); err != nil {
+		return models.Preferences{}, err
+	}
+	found, err := ps.storage.SavePreferences(ctx, username, prefs)
+	if err != nil {
+		return models.Preferences{}, err
+	}
+	if !found {
+		return models.Preferences{}, models.ErrUserNotFound
+	}
+	return prefs.Apply(models.DefaultPreferences()), nil
+}
+
+// UpdatePreferences sets the given preferences, keeping the others.
+func (ps *PreferencesSvc) UpdatePreferences(ctx context.Context, username string, patch models.PreferencesPatch) (_ models.Preferences, err error) {
+	ctx, span := startSpan(ctx, "PreferencesSvc.UpdatePreferences", attribute.String("user.username", username))
+	defer endSpan(span, &err)
+
+	if err = authorizeUserChange(ctx, username); err != nil {
+		return models.Preferences{}, err
+	}
+	if patch, err = normalizePreferences(patch); err != nil {
+		return models.Preferences{}, err
+	}
+	prefs, found, err := ps.storage.MergePreferences(ctx, username, patch)
+	if err != nil {
+		return models.Preferences{}, err
+	}
+	if !found {
+		return models.Preferences{}, models.ErrUserNotFound
+	}
+	return prefs.Apply(models.DefaultPreferences()), nil
+}
+
+// normalizePreferences validates the preferences and brings the language tag to its canonical form.
+func normalizePreferences(prefs models.PreferencesPatch) (models.PreferencesPatch, error) {
+	if prefs.Language != nil {
+		tag, err := language.Parse(*prefs.Language)
+		if err != nil {
+			return prefs, models.ErrInvalidPreferences
+		}
+		lang := tag.String()
+		prefs.Language = &lang
+	}
+	if prefs.Theme != nil {
+		switch *prefs.Theme {
+		case models.ThemeLight, models.ThemeDark, models.ThemeSystem:
+		default:
+			return prefs, models.ErrInvalidPreferences
+		}
+	}
+	return prefs, nil
+}
@@ -0,0 +1,146 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"user-service/internal/domain/models"
+)
+
+// preferencesStorage stores the preferences of a single user in memory.
+type preferencesStorage struct {
+	username string
+	prefs    models.PreferencesPatch
+}
+
+func (s *preferencesStorage) GetPreferences(_ context.Context, username string) (models.PreferencesPatch, bool, error) {
+	return s.prefs, username == s.username, nil
+}
+
+func (s *preferencesStorage) SavePreferences(_ context.Context, username string, prefs models.PreferencesPatch) (bool, error) {
+	if username != s.username {
+		return false, nil
+	}
+	s.prefs = prefs
+	return true, nil
+}
+
+func (s *preferencesStorage) MergePreferences(_ context.Context, username string, patch models.PreferencesPatch) (models.PreferencesPatch, bool, error) {
+	if username != s.username {
+		return models.PreferencesPatch{}, false, nil
+	}
+	s.prefs = s.prefs.Merge(patch)
+	return s.prefs, true, nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestPreferences(t *testing.T) {
+	stored := models.PreferencesPatch{Theme: ptr(models.ThemeDark), Notifications: &models.NotificationPreferencesPatch{SMS: ptr(true)}}
+	tests := []struct {
+		name    string
+		stored  models.PreferencesPatch
+		call    func(svc *PreferencesSvc, ctx context.Context) (models.Preferences, error)
+		want    models.Preferences
+		wantErr error
+	}{
+		{
+			name: "defaults",
+			call: func(svc *PreferencesSvc, ctx context.Context) (models.Preferences, error) {
+				return svc.GetPreferences(ctx, testUsername)
+			},
+			want: models.DefaultPreferences(),
+		},
+		{
+			name:   "stored over defaults",
+			stored: stored,
+			call: func(svc *PreferencesSvc, ctx context.Context) (models.Preferences, error) {
+				return svc.GetPreferences(ctx, testUsername)
+			},
+			want: models.Preferences{Language: "en", Theme: models.ThemeDark, Notifications: models.NotificationPreferences{Email: true, SMS: true}},
+		},
+		{
+			name:   "patch keeps the others",
+			stored: stored,
+			call: func(svc *PreferencesSvc, ctx context.Context) (models.Preferences, error) {
+				return svc.UpdatePreferences(ctx, testUsername, models.PreferencesPatch{
+					Language:      ptr("ru-ru"),
+					Notifications: &models.NotificationPreferencesPatch{Email: ptr(false)},
+				})
+			},
+			want: models.Preferences{Language: "ru-RU", Theme: models.ThemeDark, Notifications: models.NotificationPreferences{SMS: true}},
+		},
+		{
+			name:   "replace resets the others",
+			stored: stored,
+			call: func(svc *PreferencesSvc, ctx context.Context) (models.Preferences, error) {
+				return svc.SetPreferences(ctx, testUsername, models.PreferencesPatch{Language: ptr("ru")})
+			},
+			want: models.Preferences{Language: "ru", Theme: models.ThemeSystem, Notifications: models.NotificationPreferences{Email: true}},
+		},
+		{
+			name:   "replace with nothing resets all",
+			stored: stored,
+			call: func(svc *PreferencesSvc, ctx context.Context) (models.Preferences, error) {
+				return svc.SetPreferences(ctx, testUsername, models.PreferencesPatch{})
+			},
+			want: models.DefaultPreferences(),
+		},
+		{
+			name:   "invalid theme",
+			stored: stored,
+			call: func(svc *PreferencesSvc, ctx context.Context) (models.Preferences, error) {
+				return svc.UpdatePreferences(ctx, testUsername, models.PreferencesPatch{Theme: ptr("blue")})
+			},
+			wantErr: models.ErrInvalidPreferences,
+		},
+		{
+			name:   "invalid language",
+			stored: stored,
+			call: func(svc *PreferencesSvc, ctx context.Context) (models.Preferences, error) {
+				return svc.SetPreferences(ctx, testUsername, models.PreferencesPatch{Language: ptr("not a language")})
+			},
+			wantErr: models.ErrInvalidPreferences,
+		},
+		{
+			name: "unknown user",
+			call: func(svc *PreferencesSvc, ctx context.Context) (models.Preferences, error) {
+				return svc.UpdatePreferences(models.WithPrincipal(ctx, models.Principal{Username: "admin", Role: models.RoleAdmin}), "PetrPetrov1990", models.PreferencesPatch{})
+			},
+			wantErr: models.ErrUserNotFound,
+		},
+		{
+			name:   "another user",
+			stored: stored,
+			call: func(svc *PreferencesSvc, _ context.Context) (models.Preferences, error) {
+				return svc.SetPreferences(asUser("PetrPetrov1990"), testUsername, models.PreferencesPatch{})
+			},
+			wantErr: models.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &preferencesStorage{username: testUsername, prefs: tt.stored}
+			got, err := tt.call(NewPreferencesSvc(storage), asUser(testUsername))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !reflect.DeepEqual(storage.prefs, tt.stored) {
+					t.Errorf("stored preferences changed on error: %+v", storage.prefs)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			// the stored preferences give the same result on the next read
+			if read, err := NewPreferencesSvc(storage).GetPreferences(asUser(testUsername), testUsername); err != nil || read != got {
+				t.Errorf("read after write = %+v, %v, want %+v", read, err, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// randomString returns n random bytes encoded with the given function.
+func randomString(n int, encode func([]byte) string) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return encode(buf), nil
+}
+
+// sha256Hex hashes high-entropy secrets (API keys, recovery codes, authorization codes) for storage.
+// Such secrets can not be brute-forced, so a slow password hash is not needed and lookups stay cheap.
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -3,44 +3,233 @@
 package usecases
 
 import (
+	"context"
+	"time"
 	"user-service/internal/domain/models"
 	"user-service/internal/ports"
+	"user-service/pkg/infra/metrics"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type UserSvc struct {
 	storage ports.UserStorage
+	opts    UserOptions
+}
+
+type UserOptions struct {
+	InitialStatus string // status of created users, models.UserStatusActive if empty
+
+	UsernameRedirectPeriod time.Duration // former usernames resolve to the renamed user for this long
+	UsernameReusePeriod    time.Duration // former usernames cannot be taken by other users for this long
 }
 
 var _ ports.UserService = (*UserSvc)(nil)
 
 // New returns a new instance of UserSvc.
-func New(storage ports.UserStorage) *UserSvc {
+func New(storage ports.UserStorage, opts UserOptions) *UserSvc {
+	if opts.InitialStatus == "" {
+		opts.InitialStatus = models.UserStatusActive
+	}
 	return &UserSvc{
 		storage: storage,
+		opts:    opts,
 	}
 }
 
-func (us *UserSvc) CreateUser(user models.User) error {
-	if _, err := us.storage.GetUser(user.Username); err == nil {
-		return models.ErrUserAlreadyExists
+// CreateUser creates the user and returns its generated ID.
+func (us *UserSvc) CreateUser(ctx context.Context, user models.User) (_ string, err error) {
+	ctx, span := startSpan(ctx, "UserSvc.CreateUser", attribute.String("user.username", user.Username))
+	defer endSpan(span, &err)
+
+	if err = us.checkUsername(ctx, user.Username, ""); err != nil {
+		return "", err
+	}
+	if err = us.validateAttributes(ctx, user.Attributes); err != nil {
+		return "", err
 	}
-	return us.storage.SaveUser(user)
+	hash, err := hashPassword(user.Password)
+	if err != nil {
+		return "", err
+	}
+	// UUIDv7 ids are ordered by creation time, which keeps the primary key index compact
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+	user.ID = id.String()
+	user.Password = hash
+	user.Status = us.opts.InitialStatus
+	if err = us.storage.SaveUser(ctx, user); err != nil {
+		return "", err
+	}
+	metrics.UsersCreated.Inc()
+	return user.ID, nil
 }
 
-func (us *UserSvc) DeleteUser(username string) error {
-	if _, err := us.storage.GetUser(username); err != nil {
+func (us *UserSvc) DeleteUser(ctx context.Context, username string) (err error) {
+	ctx, span := startSpan(ctx, "UserSvc.DeleteUser", attribute.String("user.username", username))
+	defer endSpan(span, &err)
+
+	if err = authorizeUserChange(ctx, username); err != nil {
+		return err
+	}
+	if _, err := us.storage.GetUser(ctx, username); err != nil {
 		return models.ErrUserNotFound
 	}
-	return us.storage.DeleteUser(username)
+	if err := us.storage.DeleteUser(ctx, username); err != nil {
+		return err
+	}
+	metrics.UsersDeleted.Inc()
+	return nil
 }
 
-func (us *UserSvc) UpdateUser(username string, user models.User) error {
-	if _, err := us.storage.GetUser(username); err != nil {
+// RestoreUser restores a deleted user that has not been purged yet.
+func (us *UserSvc) RestoreUser(ctx context.Context, username string) (err error) {
+	ctx, span := startSpan(ctx, "UserSvc.RestoreUser", attribute.String("user.username", username))
+	defer endSpan(span, &err)
+
+	found, err := us.storage.RestoreUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	if !found {
 		return models.ErrUserNotFound
 	}
-	return us.storage.UpdateUser(username, user)
+	return nil
+}
+
+// PurgeDeletedUsers permanently removes the users deleted longer than retention ago.
+func (us *UserSvc) PurgeDeletedUsers(ctx context.Context, retention time.Duration) (n int64, err error) {
+	ctx, span := startSpan(ctx, "UserSvc.PurgeDeletedUsers")
+	defer endSpan(span, &err)
+
+	return us.storage.PurgeDeletedUsers(ctx, time.Now().Add(-retention))
+}
+
+func (us *UserSvc) UpdateUser(ctx context.Context, username string, user models.User) (err error) {
+	ctx, span := startSpan(ctx, "UserSvc.UpdateUser", attribute.String("user.username", username))
+	defer endSpan(span, &err)
+
+	if err = authorizeUserChange(ctx, username); err != nil {
+		return err
+	}
+	current, err := us.storage.GetUser(ctx, username)
+	if err != nil {
+		return models.ErrUserNotFound
+	}
+	// users that are not active, e.g. suspended, can not change their own account
+	principal, _ := models.PrincipalFromContext(ctx)
+	self := !principal.IsAPIKey() && !principal.IsAdmin() && principal.Username == username
+	if self && current.Status != models.UserStatusActive {
+		return models.ErrUserNotActive
+	}
+	if user.Username != username {
+		if err = us.checkUsername(ctx, user.Username, username); err != nil {
+			return err
+		}
+	}
+	if err = us.validateAttributes(ctx, user.Attributes); err != nil {
+		return err
+	}
+	// an empty password is passed on as is and keeps the current one
+	hash, err := hashPassword(user.Password)
+	if err != nil {
+		return err
+	}
+	user.Password = hash
+	if err = us.storage.UpdateUser(ctx, username, user); err != nil {
+		return err
+	}
+	metrics.UsersUpdated.Inc()
+	return nil
+}
+
+func (us *UserSvc) GetUser(ctx context.Context, username string) (_ models.GetUserResponse, err error) {
+	ctx, span := startSpan(ctx, "UserSvc.GetUser", attribute.String("user.username", username))
+	defer endSpan(span, &err)
+
+	return us.storage.GetUser(ctx, username)
+}
+
+// GetUsernameByID returns the current username of the user with the ID. Deleted users are found
+// as well, so that they can be restored by ID.
+func (us *UserSvc) GetUsernameByID(ctx context.Context, id string) (_ string, err error) {
+	ctx, span := startSpan(ctx, "UserSvc.GetUsernameByID", attribute.String("user.id", id))
+	defer endSpan(span, &err)
+
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return "", models.ErrUserNotFound
+	}
+	username, err := us.storage.GetUsernameByID(ctx, parsed.String())
+	if err != nil {
+		return "", models.ErrUserNotFound
+	}
+	return username, nil
 }
 
-func (us *UserSvc) GetUser(username string) (models.GetUserResponse, error) {
-	return us.storage.GetUser(username)
+// ResolveFormerUsername returns the current username of the user that released the username by renaming
+// within the redirect period.
+func (us *UserSvc) ResolveFormerUsername(ctx context.Context, username string) (_ string, err error) {
+	ctx, span := startSpan(ctx, "UserSvc.ResolveFormerUsername", attribute.String("user.username", username))
+	defer endSpan(span, &err)
+
+	if us.opts.UsernameRedirectPeriod <= 0 {
+		return "", models.ErrUserNotFound
+	}
+	current, found, err := us.storage.GetFormerUsernameOwner(ctx, username, time.Now().Add(-us.opts.UsernameRedirectPeriod))
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", models.ErrUserNotFound
+	}
+	return current, nil
+}
+
+// PurgeUsernameHistory removes the former usernames that no longer redirect or are reserved.
+func (us *UserSvc) PurgeUsernameHistory(ctx context.Context) (n int64, err error) {
+	ctx, span := startSpan(ctx, "UserSvc.PurgeUsernameHistory")
+	defer endSpan(span, &err)
+
+	keep := max(us.opts.UsernameRedirectPeriod, us.opts.UsernameReusePeriod)
+	return us.storage.PurgeUsernameHistory(ctx, time.Now().Add(-keep))
+}
+
+// GetUserAudit returns a page of the audit log of the user, newest entries first. The log is kept after
+// the user is deleted.
+func (us *UserSvc) GetUserAudit(ctx context.Context, username string, cursor int64, limit int) (_ models.AuditPage, err error) {
+	ctx, span := startSpan(ctx, "UserSvc.GetUserAudit", attribute.String("user.username", username))
+	defer endSpan(span, &err)
+
+	if limit <= 0 {
+		limit = models.AuditPageDefaultLimit
+	}
+	limit = min(limit, models.AuditPageMaxLimit)
+	// one more entry tells whether there is a next page
+	entries, err := us.storage.ListUserAudit(ctx, username, cursor, limit+1)
+	if err != nil {
+		return models.AuditPage{}, err
+	}
+	page := models.AuditPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.NextCursor = entries[limit-1].ID
+	}
+	return page, nil
+}
+
+// ListUsers returns a page of the users matching the filter, oldest first.
+func (us *UserSvc) ListUsers(ctx context.Context, filter models.UserFilter) (_ []models.GetUserResponse, err error) {
+	ctx, span := startSpan(ctx, "UserSvc.ListUsers")
+	defer endSpan(span, &err)
+
+	if filter.Limit <= 0 {
+		filter.Limit = models.UserPageDefaultLimit
+	}
+	filter.Limit = min(filter.Limit, models.UserPageMaxLimit)
+	filter.Offset = max(filter.Offset, 0)
+	return us.storage.ListUsers(ctx, filter)
 }
@@ -0,0 +1,63 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"user-service/internal/domain/models"
+	"user-service/internal/ports"
+)
+
+// userStorage holds a single user in memory and records the last update.
+type userStorage struct {
+	ports.UserStorage
+	user    models.GetUserResponse
+	updated *models.User
+}
+
+func (s *userStorage) GetUser(_ context.Context, username string) (models.GetUserResponse, error) {
+	if username != s.user.Username {
+		return models.GetUserResponse{}, errors.New("no rows")
+	}
+	return s.user, nil
+}
+
+func (s *userStorage) UpdateUser(_ context.Context, username string, user models.User) error {
+	if username != s.user.Username {
+		return errors.New("no rows")
+	}
+	s.updated = &user
+	return nil
+}
+
+func TestUpdateUserPassword(t *testing.T) {
+	admin := models.WithPrincipal(context.Background(), models.Principal{Username: "admin", Role: models.RoleAdmin})
+	tests := []struct {
+		name     string
+		password string
+		wantKept bool // the storage keeps the current password
+	}{
+		{name: "omitted", password: "", wantKept: true},
+		{name: "changed", password: "new password"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &userStorage{user: models.GetUserResponse{Username: "IvanIvanov2000", Status: models.UserStatusActive}}
+			svc := New(storage, UserOptions{})
+			err := svc.UpdateUser(admin, "IvanIvanov2000", models.User{Username: "IvanIvanov2000", Email: "ivanov@example.com", Password: tt.password})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := storage.updated.Password
+			if tt.wantKept {
+				if got != "" {
+					t.Errorf("password passed to the storage = %q, want empty to keep the current one", got)
+				}
+				return
+			}
+			if !checkPassword(got, tt.password) || got == tt.password {
+				t.Errorf("password passed to the storage = %q, want the hash of %q", got, tt.password)
+			}
+		})
+	}
+}
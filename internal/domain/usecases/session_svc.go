@@ -0,0 +1,134 @@
+package usecases
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+	"user-service/internal/domain/models"
+	"user-service/internal/ports"
+	"user-service/pkg/infra/logger"
+)
+
+// sessionTouchInterval limits how often the sliding expiry of a session is written to storage.
+const sessionTouchInterval = time.Minute
+
+type SessionSvc struct {
+	storage ports.SessionStorage
+	opts    SessionOptions
+}
+
+type SessionOptions struct {
+	Secret      string        // key for deriving CSRF tokens
+	IdleTTL     time.Duration // a session expires after this period of inactivity
+	MaxLifetime time.Duration // a session expires after this period regardless of activity
+}
+
+var _ ports.SessionService = (*SessionSvc)(nil)
+
+// NewSessionSvc returns a new instance of SessionSvc.
+func NewSessionSvc(storage ports.SessionStorage, opts SessionOptions) *SessionSvc {
+	return &SessionSvc{
+		storage: storage,
+		opts:    opts,
+	}
+}
+
+// CreateSession starts a session for the logged-in user and returns the opaque session token for the cookie.
+// Only the hash of the token is stored.
+func (ss *SessionSvc) CreateSession(ctx context.Context, principal models.Principal, userAgent, ip string) (string, models.Session, error) {
+	sessionToken, err := randomString(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return "", models.Session{}, err
+	}
+	id, err := randomString(8, hex.EncodeToString)
+	if err != nil {
+		return "", models.Session{}, err
+	}
+	now := time.Now()
+	session := models.Session{
+		ID:         id,
+		Username:   principal.Username,
+		Role:       principal.Role,
+		AMR:        principal.AMR,
+		UserAgent:  userAgent,
+		IP:         ip,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  ss.expiry(now, now),
+		TokenHash:  sha256Hex(sessionToken),
+	}
+	if err = ss.storage.SaveSession(ctx, session); err != nil {
+		return "", models.Session{}, err
+	}
+	return sessionToken, session, nil
+}
+
+// Authenticate resolves the session token to its user and extends the session.
+func (ss *SessionSvc) Authenticate(ctx context.Context, sessionToken string) (models.Principal, error) {
+	session, err := ss.storage.GetSessionByToken(ctx, sha256Hex(sessionToken))
+	if err != nil {
+		return models.Principal{}, models.ErrUnauthorized
+	}
+	now := time.Now()
+	if !session.ExpiresAt.After(now) {
+		if _, err = ss.storage.DeleteSession(ctx, session.Username, session.ID); err != nil {
+			logger.FromContext(ctx).Warn("failed to delete expired session", "desc", err.Error())
+		}
+		return models.Principal{}, models.ErrUnauthorized
+	}
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval {
+		if err = ss.storage.TouchSession(ctx, session.ID, ss.expiry(session.CreatedAt, now)); err != nil {
+			logger.FromContext(ctx).Warn("failed to extend session", "desc", err.Error())
+		}
+	}
+	return models.Principal{
+		UserID:    session.UserID,
+		Username:  session.Username,
+		Role:      session.Role,
+		AMR:       session.AMR,
+		SessionID: session.ID,
+	}, nil
+}
+
+// CSRFToken derives the token that must accompany state-changing requests made with the session cookie.
+// Binding it to the session prevents attackers from planting their own token in the victim's cookies.
+func (ss *SessionSvc) CSRFToken(sessionToken string) string {
+	mac := hmac.New(sha256.New, []byte(ss.opts.Secret))
+	mac.Write([]byte("csrf:" + sessionToken))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ListSessions returns the user's active sessions, marking the one with currentID.
+func (ss *SessionSvc) ListSessions(ctx context.Context, username, currentID string) ([]models.Session, error) {
+	sessions, err := ss.storage.ListSessions(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+	return sessions, nil
+}
+
+func (ss *SessionSvc) RevokeSession(ctx context.Context, username, id string) error {
+	found, err := ss.storage.DeleteSession(ctx, username, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return models.ErrSessionNotFound
+	}
+	return nil
+}
+
+// expiry returns the sliding expiration time, capped by the maximum session lifetime.
+func (ss *SessionSvc) expiry(createdAt, lastSeen time.Time) time.Time {
+	expiresAt := lastSeen.Add(ss.opts.IdleTTL)
+	if limit := createdAt.Add(ss.opts.MaxLifetime); expiresAt.After(limit) {
+		return limit
+	}
+	return expiresAt
+}
@@ -0,0 +1,67 @@
+package usecases
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"user-service/internal/domain/models"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// statusTransitions lists the statuses each status may change to. Deactivated users may be reactivated,
+// pending users can not go back to pending.
+var statusTransitions = map[string][]string{
+	models.UserStatusPending:     {models.UserStatusActive, models.UserStatusDeactivated},
+	models.UserStatusActive:      {models.UserStatusSuspended, models.UserStatusDeactivated},
+	models.UserStatusSuspended:   {models.UserStatusActive, models.UserStatusDeactivated},
+	models.UserStatusDeactivated: {models.UserStatusActive},
+}
+
+// statusNeedsReason reports whether changing to the status must be explained.
+func statusNeedsReason(status string) bool {
+	return status == models.UserStatusSuspended || status == models.UserStatusDeactivated
+}
+
+func (us *UserSvc) GetUserStatus(ctx context.Context, username string) (_ models.UserStatus, err error) {
+	ctx, span := startSpan(ctx, "UserSvc.GetUserStatus", attribute.String("user.username", username))
+	defer endSpan(span, &err)
+
+	status, err := us.storage.GetUserStatus(ctx, username)
+	if err != nil {
+		return models.UserStatus{}, models.ErrUserNotFound
+	}
+	return status, nil
+}
+
+// SetUserStatus changes the status of the user if the transition is allowed. Users that are no longer
+// active are logged out and can not log in.
+func (us *UserSvc) SetUserStatus(ctx context.Context, username string, req models.SetUserStatusRequest) (_ models.UserStatus, err error) {
+	ctx, span := startSpan(ctx, "UserSvc.SetUserStatus",
+		attribute.String("user.username", username), attribute.String("user.status", req.Status))
+	defer endSpan(span, &err)
+
+	if !slices.Contains(models.UserStatuses, req.Status) {
+		return models.UserStatus{}, models.ErrInvalidUserStatus
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if statusNeedsReason(req.Status) && req.Reason == "" {
+		return models.UserStatus{}, models.ErrStatusReasonRequired
+	}
+	current, err := us.GetUserStatus(ctx, username)
+	if err != nil {
+		return models.UserStatus{}, err
+	}
+	if !slices.Contains(statusTransitions[current.Status], req.Status) {
+		return models.UserStatus{}, models.ErrStatusTransition
+	}
+	changed, err := us.storage.SetUserStatus(ctx, username, current.Status, req.Status, req.Reason)
+	if err != nil {
+		return models.UserStatus{}, err
+	}
+	if !changed {
+		// deleted or changed by a concurrent request
+		return models.UserStatus{}, models.ErrStatusTransition
+	}
+	return us.GetUserStatus(ctx, username)
+}
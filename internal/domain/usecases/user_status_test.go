@@ -0,0 +1,106 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"user-service/internal/domain/models"
+)
+
+func (s *userStorage) GetUserStatus(_ context.Context, username string) (models.UserStatus, error) {
+	if username != s.user.Username {
+		return models.UserStatus{}, errors.New("no rows")
+	}
+	return models.UserStatus{Status: s.user.Status}, nil
+}
+
+func (s *userStorage) SetUserStatus(_ context.Context, username, from, to, _ string) (bool, error) {
+	if username != s.user.Username || from != s.user.Status {
+		return false, nil
+	}
+	s.user.Status = to
+	return true, nil
+}
+
+func TestSetUserStatusTransitions(t *testing.T) {
+	const (
+		pending     = models.UserStatusPending
+		active      = models.UserStatusActive
+		suspended   = models.UserStatusSuspended
+		deactivated = models.UserStatusDeactivated
+	)
+	allowed := map[[2]string]bool{
+		{pending, active}:        true,
+		{pending, deactivated}:   true,
+		{active, suspended}:      true,
+		{active, deactivated}:    true,
+		{suspended, active}:      true,
+		{suspended, deactivated}: true,
+		{deactivated, active}:    true,
+	}
+	// every pair of statuses, including the unchanged ones, is either allowed or forbidden
+	for _, from := range models.UserStatuses {
+		for _, to := range models.UserStatuses {
+			t.Run(from+" to "+to, func(t *testing.T) {
+				storage := &userStorage{user: models.GetUserResponse{Username: testUsername, Status: from}}
+				svc := New(storage, UserOptions{})
+				status, err := svc.SetUserStatus(context.Background(), testUsername, models.SetUserStatusRequest{Status: to, Reason: "spam"})
+				if allowed[[2]string{from, to}] {
+					if err != nil || status.Status != to {
+						t.Errorf("SetUserStatus() = %+v, %v, want %s", status, err, to)
+					}
+					return
+				}
+				if !errors.Is(err, models.ErrStatusTransition) || storage.user.Status != from {
+					t.Errorf("SetUserStatus() = %v, status %s, want %v and the status unchanged", err, storage.user.Status, models.ErrStatusTransition)
+				}
+			})
+		}
+	}
+}
+
+func TestSetUserStatusRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		req      models.SetUserStatusRequest
+		wantErr  error
+	}{
+		{name: "unknown status", req: models.SetUserStatusRequest{Status: "banned", Reason: "spam"}, wantErr: models.ErrInvalidUserStatus},
+		{name: "suspension without reason", req: models.SetUserStatusRequest{Status: models.UserStatusSuspended, Reason: "  "}, wantErr: models.ErrStatusReasonRequired},
+		{name: "deactivation without reason", req: models.SetUserStatusRequest{Status: models.UserStatusDeactivated}, wantErr: models.ErrStatusReasonRequired},
+		{name: "unknown user", username: "PetrPetrov1990", req: models.SetUserStatusRequest{Status: models.UserStatusSuspended, Reason: "spam"}, wantErr: models.ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &userStorage{user: models.GetUserResponse{Username: testUsername, Status: models.UserStatusActive}}
+			username := testUsername
+			if tt.username != "" {
+				username = tt.username
+			}
+			_, err := New(storage, UserOptions{}).SetUserStatus(context.Background(), username, tt.req)
+			if !errors.Is(err, tt.wantErr) || storage.user.Status != models.UserStatusActive {
+				t.Errorf("SetUserStatus() = %v, status %s, want %v", err, storage.user.Status, tt.wantErr)
+			}
+		})
+	}
+}
+
+// concurrentStorage changes the status between the check and the update, as a concurrent request would.
+type concurrentStorage struct {
+	*userStorage
+}
+
+func (s concurrentStorage) GetUserStatus(ctx context.Context, username string) (models.UserStatus, error) {
+	status, err := s.userStorage.GetUserStatus(ctx, username)
+	s.user.Status = models.UserStatusDeactivated
+	return status, err
+}
+
+func TestSetUserStatusConcurrentChange(t *testing.T) {
+	storage := concurrentStorage{&userStorage{user: models.GetUserResponse{Username: testUsername, Status: models.UserStatusActive}}}
+	_, err := New(storage, UserOptions{}).SetUserStatus(context.Background(), testUsername, models.SetUserStatusRequest{Status: models.UserStatusSuspended, Reason: "spam"})
+	if !errors.Is(err, models.ErrStatusTransition) || storage.user.Status != models.UserStatusDeactivated {
+		t.Errorf("SetUserStatus() = %v, status %s, want %v", err, storage.user.Status, models.ErrStatusTransition)
+	}
+}
@@ -0,0 +1,64 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"user-service/internal/domain/models"
+	"user-service/pkg/usernames"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ResolveUsername returns the username, as stored, of the user the given username refers to regardless of
+// case, Unicode form and lookalike characters. Deleted users are found as well.
+func (us *UserSvc) ResolveUsername(ctx context.Context, username string) (_ string, err error) {
+	ctx, span := startSpan(ctx, "UserSvc.ResolveUsername", attribute.String("user.username", username))
+	defer endSpan(span, &err)
+
+	stored, found, err := us.storage.FindUsername(ctx, username)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", models.ErrUserNotFound
+	}
+	return stored, nil
+}
+
+// checkUsername checks that the username is valid and can be taken by the user currently named owner, or by
+// a new user if owner is empty: no other user may have a username differing only in case, Unicode form or
+// lookalike characters, or have released it recently.
+func (us *UserSvc) checkUsername(ctx context.Context, username, owner string) error {
+	if err := usernames.Validate(username); err != nil {
+		return fmt.Errorf("%w: %w", models.ErrInvalidUsername, err)
+	}
+	existing, found, err := us.storage.FindUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !found || existing == owner:
+	case usernames.Canonical(existing) == usernames.Canonical(username):
+		return models.ErrUserAlreadyExists
+	default:
+		return fmt.Errorf("%w: %s", models.ErrUsernameConfusable, existing)
+	}
+	return us.checkUsernameReuse(ctx, username, owner)
+}
+
+// checkUsernameReuse returns ErrUsernameReserved if the username was released within the reuse period by
+// a user other than the one currently named owner. Users may take back their own former usernames.
+func (us *UserSvc) checkUsernameReuse(ctx context.Context, username, owner string) error {
+	if us.opts.UsernameReusePeriod <= 0 {
+		return nil
+	}
+	current, found, err := us.storage.GetFormerUsernameOwner(ctx, username, time.Now().Add(-us.opts.UsernameReusePeriod))
+	if err != nil {
+		return err
+	}
+	if found && current != owner {
+		return models.ErrUsernameReserved
+	}
+	return nil
+}
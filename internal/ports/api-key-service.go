@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+	"user-service/internal/domain/models"
+)
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, createdBy string, req models.CreateAPIKeyRequest) (models.CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) error
+	Authenticate(ctx context.Context, key string) (models.Principal, error)
+}
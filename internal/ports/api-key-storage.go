@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+	"user-service/internal/domain/models"
+)
+
+type APIKeyStorage interface {
+	SaveAPIKey(ctx context.Context, key models.APIKey) (int64, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (models.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) (bool, error)
+	TouchAPIKey(ctx context.Context, id int64) error
+}
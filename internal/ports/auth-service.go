@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+	"user-service/internal/domain/models"
+)
+
+type AuthService interface {
+	Login(ctx context.Context, req models.LoginRequest) (models.LoginResponse, error)
+	LoginSecondFactor(ctx context.Context, req models.LoginSecondFactorRequest) (models.LoginResponse, error)
+	Authenticate(ctx context.Context, token string) (models.Principal, error)
+	AuthenticatePassword(ctx context.Context, username, password, code string) (models.Principal, error)
+	EnrollTOTP(ctx context.Context, username string) (models.TOTPEnrollResponse, error)
+	ConfirmTOTP(ctx context.Context, username, code string) (models.TOTPConfirmResponse, error)
+	DisableTOTP(ctx context.Context, username, code string) error
+}
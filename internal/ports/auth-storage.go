@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+	"user-service/internal/domain/models"
+)
+
+type AuthStorage interface {
+	GetCredentials(ctx context.Context, username string) (models.Credentials, error)
+	GetCredentialsByID(ctx context.Context, id string) (models.Credentials, error)
+	RecordLogin(ctx context.Context, username string) error
+	SaveTOTPSecret(ctx context.Context, username, secret string) error
+	EnableTOTP(ctx context.Context, username string, recoveryCodeHashes []string) error
+	DisableTOTP(ctx context.Context, username string) error
+	UseRecoveryCode(ctx context.Context, username, codeHash string) (bool, error)
+	UseTOTPStep(ctx context.Context, username string, step int64) (bool, error)
+}
@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"context"
+	"user-service/internal/domain/models"
+)
+
+type AvatarService interface {
+	SetAvatar(ctx context.Context, username string, data []byte) (models.Avatar, error)
+	GetAvatar(ctx context.Context, username string, size int) (models.AvatarImage, error)
+}
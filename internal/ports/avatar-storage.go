@@ -0,0 +1,9 @@
+package ports
+
+import "context"
+
+type AvatarStorage interface {
+	GetAvatar(ctx context.Context, username string) (userID, avatar string, found bool, err error)
+	SetAvatar(ctx context.Context, username, avatar string) (previous string, found bool, err error)
+	GetMissingUserIDs(ctx context.Context, ids []string) ([]string, error)
+}
@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+	"user-service/internal/domain/models"
+)
+
+// BlobStorage keeps binary objects, such as images, under slash-separated keys.
+type BlobStorage interface {
+	PutBlob(ctx context.Context, key string, data []byte) error
+	GetBlob(ctx context.Context, key string) (models.Blob, bool, error)
+	ListBlobs(ctx context.Context, prefix string) ([]string, error)
+	DeleteBlobs(ctx context.Context, prefix string) error
+}
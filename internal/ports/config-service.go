@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+	"user-service/internal/domain/models"
+)
+
+type ConfigService interface {
+	ReloadConfig(ctx context.Context, trigger string) models.ConfigReloadResult
+	LastConfigReload() (models.ConfigReloadResult, error)
+	LogLevel() models.LogLevel
+	SetLogLevel(ctx context.Context, level string) (models.LogLevel, error)
+}
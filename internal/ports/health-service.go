@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"context"
+	"user-service/internal/domain/models"
+)
+
+type HealthService interface {
+	Ready(ctx context.Context) models.HealthResponse
+	SetShuttingDown()
+}
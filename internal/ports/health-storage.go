@@ -0,0 +1,10 @@
+package ports
+
+import (
+	"context"
+)
+
+type HealthStorage interface {
+	Ping(ctx context.Context) error
+	PendingMigrations(ctx context.Context) ([]string, error)
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+	"user-service/internal/domain/models"
+	"user-service/pkg/token"
+)
+
+type OIDCService interface {
+	Discovery() models.DiscoveryDocument
+	JWKS() token.JWKS
+	RegisterClient(ctx context.Context, req models.CreateOAuthClientRequest) (models.CreateOAuthClientResponse, error)
+	ListClients(ctx context.Context) ([]models.OAuthClient, error)
+	DeleteClient(ctx context.Context, clientID string) error
+	ValidateAuthorizeRequest(ctx context.Context, req models.AuthorizeRequest) (models.OAuthClient, error)
+	Authorize(ctx context.Context, req models.AuthorizeRequest, principal models.Principal) (string, error)
+	Exchange(ctx context.Context, req models.TokenRequest) (models.TokenResponse, error)
+	UserInfo(ctx context.Context, accessToken string) (models.UserInfo, error)
+}
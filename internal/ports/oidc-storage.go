@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+	"user-service/internal/domain/models"
+)
+
+type OIDCStorage interface {
+	SaveOAuthClient(ctx context.Context, client models.OAuthClient) error
+	GetOAuthClient(ctx context.Context, clientID string) (models.OAuthClient, error)
+	ListOAuthClients(ctx context.Context) ([]models.OAuthClient, error)
+	DeleteOAuthClient(ctx context.Context, clientID string) (bool, error)
+	SaveAuthorizationCode(ctx context.Context, code models.AuthorizationCode) error
+	ConsumeAuthorizationCode(ctx context.Context, codeHash string) (models.AuthorizationCode, error)
+}
@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+	"user-service/internal/domain/models"
+)
+
+type PreferencesService interface {
+	GetPreferences(ctx context.Context, username string) (models.Preferences, error)
+	SetPreferences(ctx context.Context, username string, prefs models.PreferencesPatch) (models.Preferences, error)
+	UpdatePreferences(ctx context.Context, username string, patch models.PreferencesPatch) (models.Preferences, error)
+}
@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+	"user-service/internal/domain/models"
+)
+
+type PreferencesStorage interface {
+	GetPreferences(ctx context.Context, username string) (models.PreferencesPatch, bool, error)
+	SavePreferences(ctx context.Context, username string, prefs models.PreferencesPatch) (bool, error)
+	MergePreferences(ctx context.Context, username string, patch models.PreferencesPatch) (models.PreferencesPatch, bool, error)
+}
@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+	"user-service/internal/domain/models"
+)
+
+type SessionService interface {
+	CreateSession(ctx context.Context, principal models.Principal, userAgent, ip string) (string, models.Session, error)
+	Authenticate(ctx context.Context, sessionToken string) (models.Principal, error)
+	CSRFToken(sessionToken string) string
+	ListSessions(ctx context.Context, username, currentID string) ([]models.Session, error)
+	RevokeSession(ctx context.Context, username, id string) error
+}
@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+	"time"
+	"user-service/internal/domain/models"
+)
+
+type SessionStorage interface {
+	SaveSession(ctx context.Context, session models.Session) error
+	GetSessionByToken(ctx context.Context, tokenHash string) (models.Session, error)
+	TouchSession(ctx context.Context, id string, expiresAt time.Time) error
+	ListSessions(ctx context.Context, username string) ([]models.Session, error)
+	DeleteSession(ctx context.Context, username, id string) (bool, error)
+}
@@ -2,12 +2,24 @@
 package ports
 
 import (
+	"context"
 	"user-service/internal/domain/models"
 )
 
 type UserService interface {
-	CreateUser(user models.User) error
-	GetUser(username string) (models.GetUserResponse, error)
-	UpdateUser(username string, user models.User) error
-	DeleteUser(username string) error
+	CreateUser(ctx context.Context, user models.User) (string, error)
+	GetUser(ctx context.Context, username string) (models.GetUserResponse, error)
+	GetUsernameByID(ctx context.Context, id string) (string, error)
+	ResolveUsername(ctx context.Context, username string) (string, error)
+	ResolveFormerUsername(ctx context.Context, username string) (string, error)
+	ListUsers(ctx context.Context, filter models.UserFilter) ([]models.GetUserResponse, error)
+	UpdateUser(ctx context.Context, username string, user models.User) error
+	DeleteUser(ctx context.Context, username string) error
+	GetUserStatus(ctx context.Context, username string) (models.UserStatus, error)
+	SetUserStatus(ctx context.Context, username string, req models.SetUserStatusRequest) (models.UserStatus, error)
+	RestoreUser(ctx context.Context, username string) error
+	ListAttributeDefinitions(ctx context.Context) ([]models.AttributeDefinition, error)
+	SetAttributeDefinition(ctx context.Context, name string, req models.SetAttributeDefinitionRequest) (models.AttributeDefinition, error)
+	DeleteAttributeDefinition(ctx context.Context, name string) error
+	GetUserAudit(ctx context.Context, username string, cursor int64, limit int) (models.AuditPage, error)
 }
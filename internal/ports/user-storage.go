@@ -1,12 +1,27 @@
 package ports
 
 import (
+	"context"
+	"time"
 	"user-service/internal/domain/models"
 )
 
 type UserStorage interface {
-	SaveUser(user models.User) error
-	GetUser(username string) (models.GetUserResponse, error)
-	UpdateUser(username string, user models.User) error
-	DeleteUser(username string) error
+	SaveUser(ctx context.Context, user models.User) error
+	GetUser(ctx context.Context, username string) (models.GetUserResponse, error)
+	GetUsernameByID(ctx context.Context, id string) (string, error)
+	FindUsername(ctx context.Context, username string) (string, bool, error)
+	ListUsers(ctx context.Context, filter models.UserFilter) ([]models.GetUserResponse, error)
+	UpdateUser(ctx context.Context, username string, user models.User) error
+	DeleteUser(ctx context.Context, username string) error
+	GetUserStatus(ctx context.Context, username string) (models.UserStatus, error)
+	SetUserStatus(ctx context.Context, username, from, to, reason string) (bool, error)
+	RestoreUser(ctx context.Context, username string) (bool, error)
+	PurgeDeletedUsers(ctx context.Context, deletedBefore time.Time) (int64, error)
+	GetFormerUsernameOwner(ctx context.Context, username string, releasedAfter time.Time) (string, bool, error)
+	PurgeUsernameHistory(ctx context.Context, releasedBefore time.Time) (int64, error)
+	ListAttributeDefinitions(ctx context.Context) ([]models.AttributeDefinition, error)
+	SaveAttributeDefinition(ctx context.Context, def models.AttributeDefinition) (models.AttributeDefinition, error)
+	DeleteAttributeDefinition(ctx context.Context, name string) (bool, error)
+	ListUserAudit(ctx context.Context, username string, cursor int64, limit int) ([]models.AuditEntry, error)
 }
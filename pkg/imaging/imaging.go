@@ -0,0 +1,117 @@
+// The imaging package prepares uploaded images for publishing: it decodes JPEG, PNG and GIF images, applies
+// the EXIF orientation, makes square thumbnails and encodes them again. Encoding from pixels drops all
+// metadata of the original file, such as EXIF with the camera and location.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// Formats of the decoded images as reported by image.Decode.
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatGIF  = "gif"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("image must be JPEG, PNG or GIF")
+	ErrTooLarge          = errors.New("image has too many pixels")
+)
+
+// jpegQuality is the quality of encoded JPEG thumbnails.
+const jpegQuality = 85
+
+// Decode decodes the image, rejecting images with more than maxPixels pixels before decoding them, and
+// rotates or flips JPEG images as their EXIF orientation requires. It returns the format of the image.
+func Decode(data []byte, maxPixels int) (image.Image, string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrUnsupportedFormat
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, "", ErrTooLarge
+	}
+	var img image.Image
+	switch format {
+	case FormatJPEG:
+		img, err = jpeg.Decode(bytes.NewReader(data))
+	case FormatPNG:
+		img, err = png.Decode(bytes.NewReader(data))
+	case FormatGIF:
+		// the first frame of animations
+		img, err = gif.Decode(bytes.NewReader(data))
+	default:
+		return nil, "", ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, "", ErrUnsupportedFormat
+	}
+	if format == FormatJPEG {
+		img = orient(img, jpegOrientation(data))
+	}
+	return img, format, nil
+}
+
+// OutputFormat returns the format thumbnails of images in the format are encoded in: JPEG photos stay JPEG,
+// other images become PNG to keep transparency.
+func OutputFormat(format string) string {
+	if format == FormatJPEG {
+		return FormatJPEG
+	}
+	return FormatPNG
+}
+
+// Encode writes the image in the format, FormatJPEG or FormatPNG.
+func Encode(w io.Writer, img image.Image, format string) error {
+	if format == FormatJPEG {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+	}
+	return png.Encode(w, img)
+}
+
+// Thumbnail crops the central square of the image and scales it down to size×size pixels by averaging
+// the pixels each thumbnail pixel covers. Smaller images are not scaled up.
+func Thumbnail(img image.Image, size int) *image.RGBA {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side).Add(b.Min).Add(image.Pt((b.Dx()-side)/2, (b.Dy()-side)/2))
+	src := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(src, src.Bounds(), img, crop.Min, draw.Src)
+	if side <= size {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for dy := 0; dy < size; dy++ {
+		y0, y1 := dy*side/size, (dy+1)*side/size
+		for dx := 0; dx < size; dx++ {
+			x0, x1 := dx*side/size, (dx+1)*side/size
+			var r, g, bl, a, n int
+			for y := y0; y < y1; y++ {
+				i := src.PixOffset(x0, y)
+				for x := x0; x < x1; x++ {
+					r += int(src.Pix[i])
+					g += int(src.Pix[i+1])
+					bl += int(src.Pix[i+2])
+					a += int(src.Pix[i+3])
+					i += 4
+					n++
+				}
+			}
+			j := dst.PixOffset(dx, dy)
+			dst.Pix[j] = uint8(r / n)
+			dst.Pix[j+1] = uint8(g / n)
+			dst.Pix[j+2] = uint8(bl / n)
+			dst.Pix[j+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,303 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// halves returns a w×h image with the left half red and the right half blue.
+func halves(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// byteOrder encodes the TIFF structure of the EXIF data.
+type byteOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+// exif returns the EXIF data (the TIFF structure) with the IFD entries, each a tag, a type and a value.
+func exif(order byteOrder, entries ...[3]uint16) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	if order == binary.BigEndian {
+		tiff = []byte("MM\x00*\x00\x00\x00\x08")
+	}
+	tiff = order.AppendUint16(tiff, uint16(len(entries)))
+	for _, e := range entries {
+		tiff = order.AppendUint16(tiff, e[0])
+		tiff = order.AppendUint16(tiff, e[1])
+		tiff = order.AppendUint32(tiff, 1)
+		tiff = order.AppendUint16(tiff, e[2])
+		tiff = append(tiff, 0, 0)
+	}
+	return order.AppendUint32(tiff, 0) // no next IFD
+}
+
+// orientationEXIF returns the EXIF data with the orientation tag.
+func orientationEXIF(order byteOrder, orientation uint16) []byte {
+	return exif(order, [3]uint16{0x0110, 2, 0}, [3]uint16{0x0112, 3, orientation})
+}
+
+// withSegment inserts the segment with the marker right after the start of the JPEG image.
+func withSegment(data []byte, marker byte, payload []byte) []byte {
+	segment := []byte{0xFF, marker}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func withEXIF(data, tiff []byte) []byte {
+	return withSegment(data, 0xE1, append([]byte("Exif\x00\x00"), tiff...))
+}
+
+func TestOrient(t *testing.T) {
+	// the pixels of a 3×2 image, a b c / d e f, are told apart by their red channel
+	const a, b, c, d, e, f = 10, 20, 30, 40, 50, 60
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for i, v := range []uint8{a, b, c, d, e, f} {
+		src.SetRGBA(i%3, i/3, color.RGBA{R: v, A: 255})
+	}
+	tests := []struct {
+		orientation int
+		want        [][]uint8 // rows of the result
+	}{
+		{0, [][]uint8{{a, b, c}, {d, e, f}}},
+		{1, [][]uint8{{a, b, c}, {d, e, f}}},
+		{2, [][]uint8{{c, b, a}, {f, e, d}}},
+		{3, [][]uint8{{f, e, d}, {c, b, a}}},
+		{4, [][]uint8{{d, e, f}, {a, b, c}}},
+		{5, [][]uint8{{a, d}, {b, e}, {c, f}}},
+		{6, [][]uint8{{d, a}, {e, b}, {f, c}}},
+		{7, [][]uint8{{f, c}, {e, b}, {d, a}}},
+		{8, [][]uint8{{c, f}, {b, e}, {a, d}}},
+		{9, [][]uint8{{a, b, c}, {d, e, f}}},
+	}
+	for _, tt := range tests {
+		got := orient(src, tt.orientation)
+		if b := got.Bounds(); b.Dx() != len(tt.want[0]) || b.Dy() != len(tt.want) {
+			t.Errorf("orientation %d: size %dx%d, want %dx%d", tt.orientation, b.Dx(), b.Dy(), len(tt.want[0]), len(tt.want))
+			continue
+		}
+		for y, row := range tt.want {
+			for x, want := range row {
+				if r, _, _, _ := got.At(x, y).RGBA(); uint8(r>>8) != want {
+					t.Errorf("orientation %d: pixel (%d, %d) = %d, want %d", tt.orientation, x, y, r>>8, want)
+				}
+			}
+		}
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	plain := encodeJPEG(t, halves(8, 8))
+	jfif := []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00")
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{name: "no exif", data: plain, want: 1},
+		{name: "little endian", data: withEXIF(plain, orientationEXIF(binary.LittleEndian, 6)), want: 6},
+		{name: "big endian", data: withEXIF(plain, orientationEXIF(binary.BigEndian, 8)), want: 8},
+		{name: "after other segments", data: withSegment(withEXIF(plain, orientationEXIF(binary.BigEndian, 3)), 0xE0, jfif), want: 3},
+		{name: "not a jpeg", data: []byte("\x89PNG\r\n\x1a\n"), want: 1},
+		{name: "empty", data: nil, want: 1},
+		{name: "app1 without exif", data: withSegment(plain, 0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00")), want: 1},
+		{name: "unknown byte order", data: withEXIF(plain, append([]byte("XX"), orientationEXIF(binary.LittleEndian, 6)[2:]...)), want: 1},
+		{name: "ifd offset out of range", data: withEXIF(plain, []byte("II*\x00\xff\x00\x00\x00\x01\x00")), want: 1},
+		{name: "ifd offset inside the header", data: withEXIF(plain, []byte("II*\x00\x02\x00\x00\x00")), want: 1},
+		{name: "entry count beyond the data", data: withEXIF(plain, orientationEXIF(binary.LittleEndian, 6)[:12]), want: 1},
+		{name: "orientation zero", data: withEXIF(plain, orientationEXIF(binary.LittleEndian, 0)), want: 1},
+		{name: "orientation out of range", data: withEXIF(plain, orientationEXIF(binary.LittleEndian, 9)), want: 1},
+		{name: "orientation of wrong type", data: withEXIF(plain, exif(binary.LittleEndian, [3]uint16{0x0112, 4, 6})), want: 1},
+		{name: "short tiff", data: withEXIF(plain, []byte("II*\x00")), want: 1},
+		{name: "segment length below two", data: append([]byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x01}, plain[2:]...), want: 1},
+		{name: "segment longer than the data", data: []byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF, 0xFF, 'E', 'x'}, want: 1},
+		{name: "missing marker", data: []byte{0xFF, 0xD8, 0x00, 0xE1, 0x00, 0x08}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != tt.want {
+				t.Errorf("jpegOrientation() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJPEGOrientationTruncated(t *testing.T) {
+	data := withEXIF(encodeJPEG(t, halves(8, 8)), orientationEXIF(binary.BigEndian, 6))
+	// every prefix of the file, cut inside the EXIF data or later, must be handled without panicking
+	for n := 0; n <= len(data); n++ {
+		if o := jpegOrientation(data[:n]); o < 1 || o > 8 {
+			t.Fatalf("jpegOrientation(%d bytes) = %d", n, o)
+		}
+		Decode(data[:n], 1<<20)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	var pngData, gifData bytes.Buffer
+	if err := png.Encode(&pngData, halves(20, 10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gif.Encode(&gifData, halves(20, 10), nil); err != nil {
+		t.Fatal(err)
+	}
+	jpegData := encodeJPEG(t, halves(20, 10))
+	tests := []struct {
+		name       string
+		data       []byte
+		maxPixels  int
+		wantFormat string
+		wantSize   image.Point
+		wantErr    error
+	}{
+		{name: "jpeg", data: jpegData, maxPixels: 200, wantFormat: FormatJPEG, wantSize: image.Pt(20, 10)},
+		{name: "png", data: pngData.Bytes(), maxPixels: 200, wantFormat: FormatPNG, wantSize: image.Pt(20, 10)},
+		{name: "gif", data: gifData.Bytes(), maxPixels: 200, wantFormat: FormatGIF, wantSize: image.Pt(20, 10)},
+		{name: "rotated jpeg", data: withEXIF(jpegData, orientationEXIF(binary.LittleEndian, 6)), maxPixels: 200, wantFormat: FormatJPEG, wantSize: image.Pt(10, 20)},
+		{name: "too many pixels", data: pngData.Bytes(), maxPixels: 199, wantErr: ErrTooLarge},
+		{name: "not an image", data: []byte("%PDF-1.4"), maxPixels: 200, wantErr: ErrUnsupportedFormat},
+		{name: "bmp", data: []byte("BM\x3a\x00\x00\x00\x00\x00\x00\x00\x36\x00\x00\x00"), maxPixels: 200, wantErr: ErrUnsupportedFormat},
+		{name: "corrupted image data", data: jpegData[:len(jpegData)/2], maxPixels: 200, wantErr: ErrUnsupportedFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, format, err := Decode(tt.data, tt.maxPixels)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if format != tt.wantFormat || img.Bounds().Size() != tt.wantSize {
+				t.Errorf("Decode() = %s %v, want %s %v", format, img.Bounds().Size(), tt.wantFormat, tt.wantSize)
+			}
+		})
+	}
+}
+
+func TestDecodeAppliesOrientation(t *testing.T) {
+	// rotating 90° clockwise moves the red left half to the top
+	img, _, err := Decode(withEXIF(encodeJPEG(t, halves(32, 16)), orientationEXIF(binary.BigEndian, 6)), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	top, bottom := img.At(8, 4), img.At(8, 28)
+	if r, _, b, _ := top.RGBA(); r>>8 < 200 || b>>8 > 50 {
+		t.Errorf("top = %v, want red", top)
+	}
+	if r, _, b, _ := bottom.RGBA(); r>>8 > 50 || b>>8 < 200 {
+		t.Errorf("bottom = %v, want blue", bottom)
+	}
+}
+
+func TestEncodeDropsMetadata(t *testing.T) {
+	tiff := exif(binary.LittleEndian, [3]uint16{0x0112, 3, 1})
+	tiff = append(tiff, "Canon EOS 5D GPS 55.7558N 37.6173E"...)
+	img, format, err := Decode(withEXIF(encodeJPEG(t, halves(16, 16)), tiff), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err = Encode(&out, Thumbnail(img, 8), OutputFormat(format)); err != nil {
+		t.Fatal(err)
+	}
+	for _, leaked := range []string{"Exif", "Canon", "GPS"} {
+		if bytes.Contains(out.Bytes(), []byte(leaked)) {
+			t.Errorf("the encoded thumbnail contains %q", leaked)
+		}
+	}
+	if _, err = jpeg.Decode(bytes.NewReader(out.Bytes())); err != nil {
+		t.Errorf("the thumbnail is not a JPEG: %v", err)
+	}
+}
+
+func TestOutputFormat(t *testing.T) {
+	for format, want := range map[string]string{FormatJPEG: FormatJPEG, FormatPNG: FormatPNG, FormatGIF: FormatPNG} {
+		if got := OutputFormat(format); got != want {
+			t.Errorf("OutputFormat(%s) = %s, want %s", format, got, want)
+		}
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name string
+		w, h int
+		size int
+		want int
+	}{
+		{name: "landscape", w: 300, h: 200, size: 64, want: 64},
+		{name: "portrait", w: 200, h: 300, size: 64, want: 64},
+		{name: "square", w: 512, h: 512, size: 128, want: 128},
+		{name: "not divisible", w: 100, h: 100, size: 64, want: 64},
+		{name: "exact size", w: 64, h: 80, size: 64, want: 64},
+		{name: "small image is not scaled up", w: 50, h: 80, size: 64, want: 50},
+		{name: "single pixel", w: 1, h: 1, size: 64, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Thumbnail(halves(tt.w, tt.h), tt.size).Bounds()
+			if got != image.Rect(0, 0, tt.want, tt.want) {
+				t.Errorf("Thumbnail() bounds = %v, want %dx%d", got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestThumbnailCropsCenter(t *testing.T) {
+	// a 300×100 image: red, green and blue thirds; the central square is the green one
+	img := image.NewRGBA(image.Rect(0, 0, 300, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 300; x++ {
+			img.SetRGBA(x, y, []color.RGBA{{R: 255, A: 255}, {G: 255, A: 255}, {B: 255, A: 255}}[x/100])
+		}
+	}
+	thumb := Thumbnail(img.SubImage(img.Bounds()), 10)
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			if c := thumb.RGBAAt(x, y); c != (color.RGBA{G: 255, A: 255}) {
+				t.Fatalf("pixel (%d, %d) = %v, want green", x, y, c)
+			}
+		}
+	}
+}
+
+func TestThumbnailAverages(t *testing.T) {
+	// a 2×2 black and white checkerboard scales down to a single grey pixel
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.SetRGBA(0, 0, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	img.SetRGBA(1, 1, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	img.SetRGBA(1, 0, color.RGBA{A: 255})
+	img.SetRGBA(0, 1, color.RGBA{A: 255})
+	if c := Thumbnail(img, 1).RGBAAt(0, 0); c != (color.RGBA{R: 127, G: 127, B: 127, A: 255}) {
+		t.Errorf("pixel = %v, want grey", c)
+	}
+}
@@ -4,25 +4,48 @@ package logger
 import (
 	"log/slog"
 	"os"
+	"slices"
 )
 
-var log *slog.Logger
+var (
+	log   *slog.Logger
+	level = new(slog.LevelVar) // shared by all handlers, so the level can be changed at runtime
+)
+
+// Output formats.
+const (
+	FormatJSON = "json"
+	FormatText = "text" // human-readable, for local development
+)
 
 type LoggerOptions struct {
 	IsProd bool
+	Level  string // "debug", "info", "warn" or "error"; if empty, depends on IsProd
+	Format string // FormatJSON (default) or FormatText
+
+	RedactKeys []string // attribute keys masked in addition to DefaultRedactKeys
 }
 
-// New initializes the logger. Depending on the IsProd parameter, the logging level can be either LevelInfo or LevelDebug.
+// New initializes the logger. Unless the level is set explicitly, it is LevelInfo in production and LevelDebug otherwise.
+// Personal data and credentials are redacted from the output.
 func New(opts LoggerOptions) *slog.Logger {
-	switch {
-	case opts.IsProd:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
-		)
+	l, err := ParseLevel(opts.Level, opts.IsProd)
+	if err != nil {
+		l = slog.LevelInfo
+	}
+	level.Set(l)
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch opts.Format {
+	case FormatText:
+		handler = slog.NewTextHandler(os.Stdout, handlerOpts)
 	default:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
-		)
+		handler = slog.NewJSONHandler(os.Stdout, handlerOpts)
+	}
+	keys := append(slices.Clone(DefaultRedactKeys), opts.RedactKeys...)
+	log = slog.New(NewRedactHandler(handler, RedactOptions{Keys: keys}))
+	if err != nil {
+		log.Warn("invalid log level, using info", "desc", err.Error())
 	}
 	return log
 }
@@ -34,3 +57,26 @@ func Get() *slog.Logger {
 	}
 	return log
 }
+
+// SetLevel changes the level of the logger at runtime.
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// Level returns the current level of the logger.
+func Level() slog.Level {
+	return level.Level()
+}
+
+// ParseLevel parses the level name. An empty name means LevelInfo in production and LevelDebug otherwise.
+func ParseLevel(s string, isProd bool) (slog.Level, error) {
+	if s == "" {
+		if isProd {
+			return slog.LevelInfo, nil
+		}
+		return slog.LevelDebug, nil
+	}
+	var l slog.Level
+	err := l.UnmarshalText([]byte(s))
+	return l, err
+}
@@ -0,0 +1,103 @@
+// The metrics package defines the Prometheus metrics exposed by the service at /metrics.
+//
+// Exposed metrics (besides the standard go_* and process_* ones):
+//
+//	user_service_http_requests_total{method, route, status}                  counter
+//	user_service_http_request_duration_seconds{method, route, status}        histogram
+//	user_service_http_requests_in_flight                                     gauge
+//	user_service_storage_operation_duration_seconds{operation, result}       histogram
+//	user_service_db_pool_connections{state}                                  gauge (acquired, idle, constructing, total, max)
+//	user_service_db_pool_acquires_total                                      counter
+//	user_service_db_pool_empty_acquires_total                                counter
+//	user_service_db_pool_canceled_acquires_total                             counter
+//	user_service_db_pool_acquire_duration_seconds_total                      counter
+//	user_service_users_created_total                                         counter
+//	user_service_users_updated_total                                         counter
+//	user_service_users_deleted_total                                         counter
+//
+// The route label holds the route pattern (e.g. "/user/:username"), not the requested path, to keep the
+// label cardinality bounded; requests not matching any route are labeled "unmatched".
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "user_service"
+
+// Registry holds all metrics of the service. A dedicated registry keeps metrics of imported libraries out.
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "Number of HTTP requests being served.",
+	})
+
+	StorageOperationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_operation_duration_seconds",
+		Help:      "Latency of storage operations by operation and result (ok, not_found or error).",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "result"})
+
+	UsersCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "users_created_total",
+		Help:      "Number of users created.",
+	})
+
+	UsersUpdated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "users_updated_total",
+		Help:      "Number of user updates.",
+	})
+
+	UsersDeleted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "users_deleted_total",
+		Help:      "Number of users deleted.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		HTTPRequestsInFlight,
+		StorageOperationDuration,
+		UsersCreated,
+		UsersUpdated,
+		UsersDeleted,
+	)
+}
+
+// Handler returns the HTTP handler serving the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// NewDesc returns a metric description with the service namespace, for custom collectors.
+func NewDesc(name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, labels, nil)
+}
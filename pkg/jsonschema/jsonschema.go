@@ -0,0 +1,354 @@
+// The jsonschema package validates JSON values against schemas written in a subset of JSON Schema
+// (draft 2020-12): type, enum, const, the string, number, array and object constraints. Schemas using
+// other validation keywords, such as $ref or allOf, are rejected when compiled rather than ignored.
+// Values are those produced by encoding/json: nil, bool, float64, string, []any and map[string]any.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// annotations are the keywords that do not affect validation.
+var annotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true, "default": true,
+	"examples": true, "format": true, "deprecated": true, "readOnly": true, "writeOnly": true,
+}
+
+var types = map[string]bool{
+	"null": true, "boolean": true, "integer": true, "number": true, "string": true, "array": true, "object": true,
+}
+
+// Schema is a compiled schema.
+type Schema struct {
+	types []string
+	enum  []any
+	konst *any
+
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+
+	minimum, maximum, exclusiveMinimum, exclusiveMaximum *float64
+
+	items              *Schema
+	minItems, maxItems *int
+
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *Schema // nil allows any
+	noAdditional         bool
+}
+
+// ValidationError describes why a value does not match the schema.
+type ValidationError struct {
+	Path    string // JSON pointer to the invalid value, empty for the root
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Compile parses the schema.
+func Compile(raw []byte) (*Schema, error) {
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("invalid json: %w", err)
+	}
+	return compile(doc, "")
+}
+
+func compile(doc any, path string) (*Schema, error) {
+	if b, ok := doc.(bool); ok {
+		// true accepts any value, false none
+		if b {
+			return &Schema{}, nil
+		}
+		return &Schema{enum: []any{}}, nil
+	}
+	m, ok := doc.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: schema must be an object or a boolean", pathOrRoot(path))
+	}
+	s := &Schema{}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := m[k]
+		var err error
+		switch k {
+		case "type":
+			s.types, err = compileTypes(v)
+		case "enum":
+			values, ok := v.([]any)
+			if !ok {
+				err = fmt.Errorf("must be an array")
+			}
+			s.enum = values
+		case "const":
+			s.konst = &v
+		case "minLength":
+			s.minLength, err = compileCount(v)
+		case "maxLength":
+			s.maxLength, err = compileCount(v)
+		case "pattern":
+			p, ok := v.(string)
+			if !ok {
+				err = fmt.Errorf("must be a string")
+				break
+			}
+			s.pattern, err = regexp.Compile(p)
+		case "minimum":
+			s.minimum, err = compileNumber(v)
+		case "maximum":
+			s.maximum, err = compileNumber(v)
+		case "exclusiveMinimum":
+			s.exclusiveMinimum, err = compileNumber(v)
+		case "exclusiveMaximum":
+			s.exclusiveMaximum, err = compileNumber(v)
+		case "items":
+			// nested schemas report their own paths
+			if s.items, err = compile(v, path+"/items"); err != nil {
+				return nil, err
+			}
+		case "minItems":
+			s.minItems, err = compileCount(v)
+		case "maxItems":
+			s.maxItems, err = compileCount(v)
+		case "properties":
+			props, ok := v.(map[string]any)
+			if !ok {
+				err = fmt.Errorf("must be an object")
+				break
+			}
+			s.properties = make(map[string]*Schema, len(props))
+			for name, p := range props {
+				if s.properties[name], err = compile(p, path+"/properties/"+escape(name)); err != nil {
+					return nil, err
+				}
+			}
+		case "required":
+			names, ok := v.([]any)
+			for _, n := range names {
+				name, isString := n.(string)
+				ok = ok && isString
+				s.required = append(s.required, name)
+			}
+			if !ok {
+				err = fmt.Errorf("must be an array of strings")
+			}
+		case "additionalProperties":
+			if b, isBool := v.(bool); isBool {
+				s.noAdditional = !b
+				break
+			}
+			if s.additionalProperties, err = compile(v, path+"/additionalProperties"); err != nil {
+				return nil, err
+			}
+		default:
+			if !annotations[k] {
+				err = fmt.Errorf("unsupported keyword")
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", path, k, err)
+		}
+	}
+	return s, nil
+}
+
+func compileTypes(v any) ([]string, error) {
+	var names []any
+	switch t := v.(type) {
+	case string:
+		names = []any{t}
+	case []any:
+		names = t
+	default:
+		return nil, fmt.Errorf("must be a string or an array of strings")
+	}
+	result := make([]string, 0, len(names))
+	for _, n := range names {
+		name, ok := n.(string)
+		if !ok || !types[name] {
+			return nil, fmt.Errorf("unknown type %v", n)
+		}
+		result = append(result, name)
+	}
+	return result, nil
+}
+
+func compileCount(v any) (*int, error) {
+	f, ok := v.(float64)
+	if !ok || f < 0 || f != math.Trunc(f) {
+		return nil, fmt.Errorf("must be a non-negative integer")
+	}
+	n := int(f)
+	return &n, nil
+}
+
+func compileNumber(v any) (*float64, error) {
+	f, ok := v.(float64)
+	if !ok {
+		return nil, fmt.Errorf("must be a number")
+	}
+	return &f, nil
+}
+
+// Validate checks the value against the schema and returns a *ValidationError for the first violation found.
+func (s *Schema) Validate(v any) error {
+	return s.validate(v, "")
+}
+
+func (s *Schema) validate(v any, path string) error {
+	fail := func(format string, args ...any) error {
+		return &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)}
+	}
+	if len(s.types) > 0 && !hasType(v, s.types) {
+		return fail("must be %s", strings.Join(s.types, " or "))
+	}
+	if s.enum != nil && !contains(s.enum, v) {
+		return fail("must be one of the allowed values")
+	}
+	if s.konst != nil && !equal(*s.konst, v) {
+		return fail("must be %v", *s.konst)
+	}
+	switch v := v.(type) {
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.minLength != nil && n < *s.minLength {
+			return fail("must be at least %d characters long", *s.minLength)
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			return fail("must be at most %d characters long", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			return fail("must match the pattern %s", s.pattern)
+		}
+	case float64:
+		if s.minimum != nil && v < *s.minimum {
+			return fail("must be at least %v", *s.minimum)
+		}
+		if s.maximum != nil && v > *s.maximum {
+			return fail("must be at most %v", *s.maximum)
+		}
+		if s.exclusiveMinimum != nil && v <= *s.exclusiveMinimum {
+			return fail("must be greater than %v", *s.exclusiveMinimum)
+		}
+		if s.exclusiveMaximum != nil && v >= *s.exclusiveMaximum {
+			return fail("must be less than %v", *s.exclusiveMaximum)
+		}
+	case []any:
+		if s.minItems != nil && len(v) < *s.minItems {
+			return fail("must have at least %d items", *s.minItems)
+		}
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			return fail("must have at most %d items", *s.maxItems)
+		}
+		if s.items != nil {
+			for i, item := range v {
+				if err := s.items.validate(item, fmt.Sprintf("%s/%d", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]any:
+		for _, name := range s.required {
+			if _, ok := v[name]; !ok {
+				return fail("missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := path + "/" + escape(name)
+			if p, ok := s.properties[name]; ok {
+				if err := p.validate(v[name], child); err != nil {
+					return err
+				}
+				continue
+			}
+			if s.noAdditional {
+				return &ValidationError{Path: child, Message: "property is not allowed"}
+			}
+			if s.additionalProperties != nil {
+				if err := s.additionalProperties.validate(v[name], child); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func hasType(v any, names []string) bool {
+	for _, name := range names {
+		switch t := v.(type) {
+		case nil:
+			if name == "null" {
+				return true
+			}
+		case bool:
+			if name == "boolean" {
+				return true
+			}
+		case float64:
+			if name == "number" || name == "integer" && t == math.Trunc(t) {
+				return true
+			}
+		case string:
+			if name == "string" {
+				return true
+			}
+		case []any:
+			if name == "array" {
+				return true
+			}
+		case map[string]any:
+			if name == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func contains(values []any, v any) bool {
+	for _, e := range values {
+		if equal(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func equal(a, b any) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// escape escapes a property name for a JSON pointer.
+func escape(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
@@ -0,0 +1,159 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		value   string
+		wantErr string // path and message of the validation error, empty if the value is valid
+	}{
+		{name: "true schema", schema: `true`, value: `{"a": [1, null]}`},
+		{name: "false schema", schema: `false`, value: `1`, wantErr: "must be one of the allowed values"},
+		{name: "empty schema", schema: `{}`, value: `"anything"`},
+		{name: "annotations", schema: `{"$schema": "https://json-schema.org/draft/2020-12/schema", "title": "t", "description": "d", "default": 1, "examples": [1], "format": "email", "deprecated": true}`, value: `"not an email"`},
+
+		{name: "type string", schema: `{"type": "string"}`, value: `"Europe/Moscow"`},
+		{name: "type string mismatch", schema: `{"type": "string"}`, value: `1`, wantErr: "must be string"},
+		{name: "type null", schema: `{"type": "null"}`, value: `null`},
+		{name: "type boolean", schema: `{"type": "boolean"}`, value: `"true"`, wantErr: "must be boolean"},
+		{name: "type integer", schema: `{"type": "integer"}`, value: `42`},
+		{name: "type integer with zero fraction", schema: `{"type": "integer"}`, value: `42.0`},
+		{name: "type integer mismatch", schema: `{"type": "integer"}`, value: `4.2`, wantErr: "must be integer"},
+		{name: "type number", schema: `{"type": "number"}`, value: `4.2`},
+		{name: "type array", schema: `{"type": "array"}`, value: `{}`, wantErr: "must be array"},
+		{name: "type object", schema: `{"type": "object"}`, value: `[]`, wantErr: "must be object"},
+		{name: "type list", schema: `{"type": ["string", "null"]}`, value: `null`},
+		{name: "type list mismatch", schema: `{"type": ["string", "null"]}`, value: `false`, wantErr: "must be string or null"},
+
+		{name: "enum", schema: `{"enum": ["ru", "en", 1, null]}`, value: `"en"`},
+		{name: "enum number", schema: `{"enum": ["ru", "en", 1, null]}`, value: `1`},
+		{name: "enum mismatch", schema: `{"enum": ["ru", "en"]}`, value: `"de"`, wantErr: "must be one of the allowed values"},
+		{name: "enum object", schema: `{"enum": [{"a": [1]}]}`, value: `{"a": [1]}`},
+		{name: "const", schema: `{"const": "engineering"}`, value: `"engineering"`},
+		{name: "const mismatch", schema: `{"const": "engineering"}`, value: `"sales"`, wantErr: "must be engineering"},
+
+		{name: "minLength", schema: `{"minLength": 2}`, value: `"ab"`},
+		{name: "minLength counts characters", schema: `{"minLength": 3}`, value: `"яя"`, wantErr: "must be at least 3 characters long"},
+		{name: "maxLength", schema: `{"maxLength": 2}`, value: `"яя"`},
+		{name: "maxLength exceeded", schema: `{"maxLength": 2}`, value: `"abc"`, wantErr: "must be at most 2 characters long"},
+		{name: "pattern", schema: `{"pattern": "^[A-Z]{2}$"}`, value: `"RU"`},
+		{name: "pattern is not anchored", schema: `{"pattern": "[0-9]"}`, value: `"a1b"`},
+		{name: "pattern mismatch", schema: `{"pattern": "^[A-Z]{2}$"}`, value: `"RUS"`, wantErr: "must match the pattern ^[A-Z]{2}$"},
+		{name: "string keywords ignore other types", schema: `{"minLength": 5, "pattern": "^a"}`, value: `1`},
+
+		{name: "minimum", schema: `{"minimum": 1}`, value: `1`},
+		{name: "minimum violated", schema: `{"minimum": 1}`, value: `0.5`, wantErr: "must be at least 1"},
+		{name: "maximum", schema: `{"maximum": 10}`, value: `10`},
+		{name: "maximum violated", schema: `{"maximum": 10}`, value: `11`, wantErr: "must be at most 10"},
+		{name: "exclusiveMinimum", schema: `{"exclusiveMinimum": 0}`, value: `0.1`},
+		{name: "exclusiveMinimum violated", schema: `{"exclusiveMinimum": 0}`, value: `0`, wantErr: "must be greater than 0"},
+		{name: "exclusiveMaximum", schema: `{"exclusiveMaximum": 100}`, value: `99.9`},
+		{name: "exclusiveMaximum violated", schema: `{"exclusiveMaximum": 100}`, value: `100`, wantErr: "must be less than 100"},
+		{name: "number keywords ignore other types", schema: `{"minimum": 5}`, value: `"1"`},
+
+		{name: "items", schema: `{"items": {"type": "string"}}`, value: `["a", "b"]`},
+		{name: "items mismatch", schema: `{"items": {"type": "string"}}`, value: `["a", 2]`, wantErr: "/1: must be string"},
+		{name: "minItems", schema: `{"minItems": 1}`, value: `[]`, wantErr: "must have at least 1 items"},
+		{name: "maxItems", schema: `{"maxItems": 1}`, value: `[1, 2]`, wantErr: "must have at most 1 items"},
+		{name: "array keywords ignore other types", schema: `{"minItems": 1}`, value: `{}`},
+
+		{name: "properties", schema: `{"properties": {"floor": {"type": "integer"}}}`, value: `{"floor": 3, "room": "301"}`},
+		{name: "properties mismatch", schema: `{"properties": {"floor": {"type": "integer"}}}`, value: `{"floor": "3"}`, wantErr: "/floor: must be integer"},
+		{name: "required", schema: `{"required": ["floor"]}`, value: `{"floor": 3}`},
+		{name: "required missing", schema: `{"required": ["floor", "room"]}`, value: `{"floor": 3}`, wantErr: `missing required property "room"`},
+		{name: "additionalProperties false", schema: `{"properties": {"a": {}}, "additionalProperties": false}`, value: `{"a": 1, "b": 2}`, wantErr: "/b: property is not allowed"},
+		{name: "additionalProperties true", schema: `{"properties": {"a": {}}, "additionalProperties": true}`, value: `{"a": 1, "b": 2}`},
+		{name: "additionalProperties schema", schema: `{"properties": {"a": {}}, "additionalProperties": {"type": "number"}}`, value: `{"a": "x", "b": 2}`},
+		{name: "additionalProperties schema mismatch", schema: `{"additionalProperties": {"type": "number"}}`, value: `{"b": "2"}`, wantErr: "/b: must be number"},
+		{name: "object keywords ignore other types", schema: `{"required": ["a"]}`, value: `[]`},
+
+		{
+			name:    "nested path",
+			schema:  `{"properties": {"a/b": {"items": {"properties": {"c~d": {"type": "string"}}}}}}`,
+			value:   `{"a/b": [{"c~d": "ok"}, {"c~d": 1}]}`,
+			wantErr: "/a~1b/1/c~0d: must be string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Compile([]byte(tt.schema))
+			if err != nil {
+				t.Fatalf("Compile(%s) = %v", tt.schema, err)
+			}
+			var value any
+			if err = json.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatal(err)
+			}
+			err = s.Validate(value)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate(%s) = %v, want valid", tt.value, err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate(%s) = %v, want a *ValidationError", tt.value, err)
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("Validate(%s) = %q, want %q", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompileRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr string
+	}{
+		{name: "invalid json", schema: `{"type": `, wantErr: "invalid json"},
+		{name: "not a schema", schema: `"string"`, wantErr: "/: schema must be an object or a boolean"},
+		{name: "unknown type", schema: `{"type": "date"}`, wantErr: "/type: unknown type date"},
+		{name: "type of wrong kind", schema: `{"type": 1}`, wantErr: "/type: must be a string or an array of strings"},
+		{name: "enum not an array", schema: `{"enum": "ru"}`, wantErr: "/enum: must be an array"},
+		{name: "negative count", schema: `{"minLength": -1}`, wantErr: "/minLength: must be a non-negative integer"},
+		{name: "fractional count", schema: `{"maxItems": 1.5}`, wantErr: "/maxItems: must be a non-negative integer"},
+		{name: "invalid pattern", schema: `{"pattern": "["}`, wantErr: "/pattern: error parsing regexp"},
+		{name: "pattern not a string", schema: `{"pattern": 1}`, wantErr: "/pattern: must be a string"},
+		{name: "bound not a number", schema: `{"minimum": "1"}`, wantErr: "/minimum: must be a number"},
+		{name: "properties not an object", schema: `{"properties": []}`, wantErr: "/properties: must be an object"},
+		{name: "required not strings", schema: `{"required": ["a", 1]}`, wantErr: "/required: must be an array of strings"},
+		{name: "required not an array", schema: `{"required": "a"}`, wantErr: "/required: must be an array of strings"},
+		{name: "nested invalid schema", schema: `{"properties": {"a": {"items": {"maxLength": "2"}}}}`, wantErr: "/properties/a/items/maxLength: must be a non-negative integer"},
+		{name: "invalid additionalProperties", schema: `{"additionalProperties": 1}`, wantErr: "/additionalProperties: schema must be an object or a boolean"},
+
+		// keywords outside of the supported subset are rejected, not ignored
+		{name: "$ref", schema: `{"$ref": "#/$defs/a"}`, wantErr: "/$ref: unsupported keyword"},
+		{name: "$defs", schema: `{"$defs": {"a": {}}}`, wantErr: "/$defs: unsupported keyword"},
+		{name: "allOf", schema: `{"allOf": [{"type": "string"}]}`, wantErr: "/allOf: unsupported keyword"},
+		{name: "anyOf", schema: `{"anyOf": [{"type": "string"}]}`, wantErr: "/anyOf: unsupported keyword"},
+		{name: "oneOf", schema: `{"oneOf": [{"type": "string"}]}`, wantErr: "/oneOf: unsupported keyword"},
+		{name: "not", schema: `{"not": {"type": "string"}}`, wantErr: "/not: unsupported keyword"},
+		{name: "if", schema: `{"if": {"type": "string"}}`, wantErr: "/if: unsupported keyword"},
+		{name: "multipleOf", schema: `{"multipleOf": 2}`, wantErr: "/multipleOf: unsupported keyword"},
+		{name: "uniqueItems", schema: `{"uniqueItems": true}`, wantErr: "/uniqueItems: unsupported keyword"},
+		{name: "patternProperties", schema: `{"patternProperties": {"^a": {}}}`, wantErr: "/patternProperties: unsupported keyword"},
+		{name: "nested unsupported keyword", schema: `{"items": {"contains": {}}}`, wantErr: "/items/contains: unsupported keyword"},
+		{name: "misspelled keyword", schema: `{"maxlength": 2}`, wantErr: "/maxlength: unsupported keyword"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Compile([]byte(tt.schema))
+			if err == nil {
+				t.Fatalf("Compile(%s) = %+v, want an error", tt.schema, s)
+			}
+			if !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("Compile(%s) = %q, want %q", tt.schema, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func newTestMemoryStore() (*MemoryStore, *time.Time) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	m := NewMemoryStore()
+	m.now = func() time.Time { return now }
+	return m, &now
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	m, now := newTestMemoryStore()
+	limit := Limit{Rate: 0.5, Burst: 3} // a token every 2 seconds
+	ctx := context.Background()
+
+	steps := []struct {
+		after   time.Duration
+		tokens  float64
+		allowed bool
+	}{
+		{0, 2, true}, // a new bucket is full
+		{0, 1, true},
+		{0, 0, true},
+		{0, 0, false},
+		{time.Second, 0.5, false},
+		{time.Second, 0, true},
+		{time.Hour, 2, true}, // refilled up to the burst only
+	}
+	for i, s := range steps {
+		*now = now.Add(s.after)
+		tokens, allowed, err := m.Take(ctx, "client", limit)
+		if err != nil || tokens != s.tokens || allowed != s.allowed {
+			t.Errorf("step %d: Take() = %v, %t, %v, want %v, %t", i, tokens, allowed, err, s.tokens, s.allowed)
+		}
+	}
+
+	// buckets are separate per key
+	if tokens, allowed, _ := m.Take(ctx, "other", limit); tokens != 2 || !allowed {
+		t.Errorf("Take(other) = %v, %t, want a full bucket", tokens, allowed)
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	m, now := newTestMemoryStore()
+	ctx := context.Background()
+	fast := Limit{Rate: 1, Burst: 10}           // refilled from empty in 10s
+	slow := Limit{Rate: 10.0 / 3600, Burst: 10} // 10/h, refilled from empty in an hour
+
+	for i := 0; i < 10; i++ {
+		m.Take(ctx, "fast", fast)
+		m.Take(ctx, "slow", slow)
+	}
+	*now = now.Add(sweepInterval)
+	m.Take(ctx, "other", fast)
+	if _, ok := m.buckets["fast"]; ok {
+		t.Error("the full bucket is kept")
+	}
+	if _, ok := m.buckets["slow"]; !ok {
+		t.Fatal("the bucket being refilled is removed")
+	}
+
+	// the bucket is kept until it is refilled, however long it is idle
+	start := now.Add(-sweepInterval)
+	*now = start.Add(time.Hour - time.Second)
+	m.Take(ctx, "other", fast)
+	if _, ok := m.buckets["slow"]; !ok {
+		t.Fatal("the bucket is removed before it is refilled")
+	}
+	*now = start.Add(time.Hour + sweepInterval)
+	m.Take(ctx, "other", fast)
+	if _, ok := m.buckets["slow"]; ok {
+		t.Error("the bucket is kept after it is refilled")
+	}
+	// the removed bucket starts full, as it would have been
+	if tokens, allowed, _ := m.Take(ctx, "slow", slow); tokens != 9 || !allowed {
+		t.Errorf("Take() after the sweep = %v, %t, want 9, true", tokens, allowed)
+	}
+}
@@ -0,0 +1,150 @@
+// The ratelimit package implements token bucket rate limiting with pluggable bucket storage.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultRule is the name of the rule applied to routes without their own rule.
+const DefaultRule = "default"
+
+// Limit describes a token bucket: it holds up to Burst tokens and is refilled at Rate tokens per second.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Result is the state of the bucket after a request was counted.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next request is allowed, if it was denied
+}
+
+// Store keeps the buckets. Implementations must update a bucket atomically, so that several replicas
+// sharing the store enforce a common limit.
+type Store interface {
+	// Take refills the bucket with the given key and takes one token from it, if available.
+	// It returns the number of tokens left and whether the token was taken.
+	Take(ctx context.Context, key string, limit Limit) (tokens float64, allowed bool, err error)
+}
+
+// Rules maps route keys ("POST /user") to their limits.
+type Rules map[string]Limit
+
+type Limiter struct {
+	store Store
+	rules atomic.Pointer[Rules]
+}
+
+// NewLimiter returns a limiter applying the rules with buckets kept in the store.
+func NewLimiter(store Store, rules Rules) *Limiter {
+	l := &Limiter{
+		store: store,
+	}
+	l.SetRules(rules)
+	return l
+}
+
+// SetRules replaces the rules. Requests being counted concurrently use either the old or the new rules.
+func (l *Limiter) SetRules(rules Rules) {
+	l.rules.Store(&rules)
+}
+
+// Allow counts a request of the client to the route. ok is false if neither the route nor the default
+// rule has a limit, in which case the request is not limited.
+func (l *Limiter) Allow(ctx context.Context, route, client string) (res Result, ok bool, err error) {
+	rules := *l.rules.Load()
+	limit, found := rules[route]
+	if !found {
+		if limit, found = rules[DefaultRule]; !found {
+			return Result{}, false, nil
+		}
+		route = DefaultRule
+	}
+	tokens, allowed, err := l.store.Take(ctx, route+"|"+client, limit)
+	if err != nil {
+		return Result{}, false, err
+	}
+	return result(limit, tokens, allowed), true, nil
+}
+
+func result(limit Limit, tokens float64, allowed bool) Result {
+	res := Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     secondsToDuration((float64(limit.Burst) - tokens) / limit.Rate),
+	}
+	if !allowed {
+		res.RetryAfter = secondsToDuration((1 - tokens) / limit.Rate)
+	}
+	return res
+}
+
+func secondsToDuration(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(s)) * time.Second
+}
+
+// ParseRules parses rules in the form "<route>=<count>/<s|m|h>[:<burst>]" separated by semicolons, e.g.
+// "default=20/s:40;POST /user=10/m;POST /auth/login=5/m:10". The burst defaults to the count.
+func ParseRules(s string) (Rules, error) {
+	rules := Rules{}
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		route, spec, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("rate limit rule %q: missing '='", part)
+		}
+		limit, err := parseLimit(strings.TrimSpace(spec))
+		if err != nil {
+			return nil, fmt.Errorf("rate limit rule %q: %w", part, err)
+		}
+		rules[strings.Join(strings.Fields(route), " ")] = limit
+	}
+	return rules, nil
+}
+
+func parseLimit(spec string) (Limit, error) {
+	spec, burstStr, hasBurst := strings.Cut(spec, ":")
+	countStr, unit, found := strings.Cut(spec, "/")
+	if !found {
+		return Limit{}, fmt.Errorf("expected <count>/<unit>")
+	}
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count <= 0 {
+		return Limit{}, fmt.Errorf("invalid count %q", countStr)
+	}
+	var period time.Duration
+	switch unit {
+	case "s":
+		period = time.Second
+	case "m":
+		period = time.Minute
+	case "h":
+		period = time.Hour
+	default:
+		return Limit{}, fmt.Errorf("invalid unit %q, expected s, m or h", unit)
+	}
+	burst := count
+	if hasBurst {
+		if burst, err = strconv.Atoi(burstStr); err != nil || burst <= 0 {
+			return Limit{}, fmt.Errorf("invalid burst %q", burstStr)
+		}
+	}
+	return Limit{Rate: float64(count) / period.Seconds(), Burst: burst}, nil
+}
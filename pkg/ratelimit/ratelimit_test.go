@@ -0,0 +1,124 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules(" default=20/s:40; POST   /user=10/m;;POST /auth/login=5/h:10 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Rules{
+		DefaultRule:        {Rate: 20, Burst: 40},
+		"POST /user":       {Rate: 10.0 / 60, Burst: 10},
+		"POST /auth/login": {Rate: 5.0 / 3600, Burst: 10},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("ParseRules() = %v, want %v", rules, want)
+	}
+	for route, limit := range want {
+		if rules[route] != limit {
+			t.Errorf("rule %q = %+v, want %+v", route, rules[route], limit)
+		}
+	}
+
+	for _, s := range []string{
+		"default",
+		"default=20",
+		"default=0/s",
+		"default=-1/s",
+		"default=x/s",
+		"default=20/d",
+		"default=20/s:0",
+		"default=20/s:x",
+	} {
+		if _, err := ParseRules(s); err == nil {
+			t.Errorf("ParseRules(%q) succeeded", s)
+		}
+	}
+}
+
+func TestResult(t *testing.T) {
+	limit := Limit{Rate: 0.5, Burst: 10} // a token every 2 seconds
+	tests := []struct {
+		name    string
+		tokens  float64
+		allowed bool
+		want    Result
+	}{
+		{
+			name: "full", tokens: 10, allowed: true,
+			want: Result{Allowed: true, Limit: 10, Remaining: 10},
+		},
+		{
+			name: "one taken", tokens: 9, allowed: true,
+			want: Result{Allowed: true, Limit: 10, Remaining: 9, Reset: 2 * time.Second},
+		},
+		{
+			name: "fraction is rounded down and up", tokens: 8.3, allowed: true,
+			want: Result{Allowed: true, Limit: 10, Remaining: 8, Reset: 4 * time.Second},
+		},
+		{
+			name: "last token taken", tokens: 0, allowed: true,
+			want: Result{Allowed: true, Limit: 10, Remaining: 0, Reset: 20 * time.Second},
+		},
+		{
+			name: "denied", tokens: 0.25, allowed: false,
+			want: Result{Limit: 10, Remaining: 0, Reset: 20 * time.Second, RetryAfter: 2 * time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := result(limit, tt.tokens, tt.allowed); got != tt.want {
+				t.Errorf("result(%v, %t) = %+v, want %+v", tt.tokens, tt.allowed, got, tt.want)
+			}
+		})
+	}
+}
+
+// recordingStore remembers the keys and limits it was asked for.
+type recordingStore struct {
+	keys   []string
+	limits []Limit
+}
+
+func (s *recordingStore) Take(_ context.Context, key string, limit Limit) (float64, bool, error) {
+	s.keys = append(s.keys, key)
+	s.limits = append(s.limits, limit)
+	return float64(limit.Burst) - 1, true, nil
+}
+
+func TestLimiterAllow(t *testing.T) {
+	login := Limit{Rate: 1, Burst: 5}
+	def := Limit{Rate: 10, Burst: 20}
+	store := &recordingStore{}
+	l := NewLimiter(store, Rules{"POST /auth/login": login})
+	ctx := context.Background()
+
+	if _, ok, err := l.Allow(ctx, "GET /user", "10.0.0.1"); ok || err != nil {
+		t.Errorf("Allow() without a rule = %t, %v, want not limited", ok, err)
+	}
+	if res, ok, err := l.Allow(ctx, "POST /auth/login", "10.0.0.1"); !ok || err != nil || res.Limit != 5 {
+		t.Errorf("Allow() = %+v, %t, %v, want the login limit", res, ok, err)
+	}
+
+	l.SetRules(Rules{"POST /auth/login": login, DefaultRule: def})
+	if _, ok, err := l.Allow(ctx, "GET /user", "10.0.0.1"); !ok || err != nil {
+		t.Errorf("Allow() with a default rule = %t, %v, want limited", ok, err)
+	}
+	if _, _, err := l.Allow(ctx, "GET /user/:username", "10.0.0.2"); err != nil {
+		t.Fatal(err)
+	}
+
+	// routes without a rule share the bucket of the default rule, per client
+	wantKeys := []string{"POST /auth/login|10.0.0.1", "default|10.0.0.1", "default|10.0.0.2"}
+	wantLimits := []Limit{login, def, def}
+	for i := range wantKeys {
+		if store.keys[i] != wantKeys[i] || store.limits[i] != wantLimits[i] {
+			t.Errorf("Take(%q, %+v), want Take(%q, %+v)", store.keys[i], store.limits[i], wantKeys[i], wantLimits[i])
+		}
+	}
+}
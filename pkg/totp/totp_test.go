@@ -0,0 +1,156 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key of RFC 4226 Appendix D and RFC 6238 Appendix B.
+var rfcSecret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestHOTP(t *testing.T) {
+	// RFC 4226 Appendix D
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range want {
+		if got := hotp([]byte("12345678901234567890"), uint64(counter)); got != code {
+			t.Errorf("hotp(%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestCode(t *testing.T) {
+	// RFC 6238 Appendix B, SHA-1: the 6-digit codes are the last digits of the 8-digit ones
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, tt := range tests {
+		want := tt.want[len(tt.want)-Digits:]
+		got, err := Code(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil || got != want {
+			t.Errorf("Code(%d) = %s, %v, want %s", tt.unix, got, err, want)
+		}
+		// authenticator apps may show the secret in lower case
+		if got, _ := Code(strings.ToLower(rfcSecret), time.Unix(tt.unix, 0)); got != want {
+			t.Errorf("Code(lower case secret, %d) = %s, want %s", tt.unix, got, want)
+		}
+	}
+	if _, err := Code("not base32!", time.Now()); err == nil {
+		t.Error("Code(invalid secret) succeeded")
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	const step = 1234567890 / 30
+	now := time.Unix(step*30, 0)
+	code, err := Code(rfcSecret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		at   time.Time
+		ok   bool
+	}{
+		{"same step", now, true},
+		{"end of the step", now.Add(Period - time.Second), true},
+		{"previous step", now.Add(-Period), true},
+		{"next step", now.Add(Period), true},
+		{"two steps earlier", now.Add(-2 * Period), false},
+		{"two steps later", now.Add(2 * Period), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Validate(rfcSecret, code, tt.at)
+			if ok != tt.ok {
+				t.Fatalf("Validate() ok = %t, want %t", ok, tt.ok)
+			}
+			// the step is the one the code was generated for, not the current one, so that a code
+			// accepted as the next step cannot be replayed in the following one
+			if ok && got != step {
+				t.Errorf("Validate() step = %d, want %d", got, step)
+			}
+		})
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, err := Code(rfcSecret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := generateSecret(t)
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+	}{
+		{"wrong code", rfcSecret, "000000"},
+		{"other secret", other, code},
+		{"8 digits", rfcSecret, "89005924"},
+		{"short", rfcSecret, code[:Digits-1]},
+		{"empty", rfcSecret, ""},
+		{"invalid secret", "not base32!", code},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if step, ok := Validate(tt.secret, tt.code, now); ok {
+				t.Errorf("Validate(%q) accepted the code for step %d", tt.code, step)
+			}
+		})
+	}
+}
+
+func TestValidateReplay(t *testing.T) {
+	// Validate itself has no state: it returns the same step for every use of a code, which lets the
+	// callers reject the steps not later than the last accepted one
+	now := time.Unix(1234567890, 0)
+	code, err := Code(rfcSecret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, ok := Validate(rfcSecret, code, now)
+	if !ok {
+		t.Fatal("Validate() rejected the current code")
+	}
+	again, ok := Validate(rfcSecret, code, now.Add(Period))
+	if !ok || again != first {
+		t.Errorf("Validate() in the next step = %d, %t, want %d", again, ok, first)
+	}
+}
+
+func generateSecret(t *testing.T) string {
+	t.Helper()
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key, err := encoding.DecodeString(secret); err != nil || len(key) != secretSize {
+		t.Fatalf("GenerateSecret() = %q, decodes to %d bytes (%v), want %d", secret, len(key), err, secretSize)
+	}
+	return secret
+}
+
+func TestURI(t *testing.T) {
+	u, err := url.Parse(URI("User Service", "IvanIvanov2000", rfcSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/User Service:IvanIvanov2000" {
+		t.Errorf("URI() = %s, want otpauth://totp/User Service:IvanIvanov2000", u)
+	}
+	if q.Get("secret") != rfcSecret || q.Get("issuer") != "User Service" || q.Get("digits") != "6" || q.Get("period") != "30" {
+		t.Errorf("URI() query = %v", q)
+	}
+}
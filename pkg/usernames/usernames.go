@@ -0,0 +1,95 @@
+// The usernames package compares usernames the way people read them. Usernames that differ only in case,
+// in the Unicode form of the same characters (e.g. fullwidth letters) or in lookalike characters from
+// other scripts (Latin "a" and Cyrillic "а") identify the same user, while the form chosen by the user is
+// kept for display.
+package usernames
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength is the maximum length of a username in characters.
+const MaxLength = 255
+
+var (
+	ErrEmpty        = errors.New("username must not be empty")
+	ErrTooLong      = errors.New("username must be at most 255 characters")
+	ErrInvalidChars = errors.New("username must not contain spaces, control characters or '/'")
+	ErrMixedScripts = errors.New("username must not mix letters of different scripts")
+)
+
+// scripts are the scripts a username's letters must not mix; letters of other scripts are not restricted.
+var scripts = map[string]*unicode.RangeTable{
+	"Latin":    unicode.Latin,
+	"Cyrillic": unicode.Cyrillic,
+	"Greek":    unicode.Greek,
+	"Armenian": unicode.Armenian,
+}
+
+// confusables maps characters that look like Latin letters after case folding to them. It covers the common
+// lookalikes of the Unicode confusables list (UTS #39) rather than the whole list. Digits are not mapped:
+// UTS #39 folds 0 into o and 1 into l, but digits are common in usernames, and folding them would make
+// names such as user10 and userlo the same user.
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p', 'с': 'c',
+	'т': 't', 'у': 'y', 'х': 'x', 'ѕ': 's', 'і': 'i', 'ї': 'i', 'ј': 'j', 'һ': 'h', 'ԁ': 'd', 'ԛ': 'q',
+	'ԝ': 'w', 'ӏ': 'l',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p', 'τ': 't',
+	'υ': 'u', 'χ': 'x', 'ω': 'w',
+	// Armenian
+	'օ': 'o', 'ս': 'u', 'հ': 'h', 'ց': 'g',
+	// Latin letters looking like other Latin letters
+	'ɡ': 'g', 'ı': 'i',
+}
+
+var folder = cases.Fold()
+
+// Canonical returns the form used to compare usernames regardless of case and Unicode representation:
+// NFKC normalization, case folding and NFKC again, as case folding may denormalize the string.
+func Canonical(username string) string {
+	return norm.NFKC.String(folder.String(norm.NFKC.String(username)))
+}
+
+// Skeleton returns the canonical form with lookalike characters replaced, so that usernames that can be
+// mistaken for each other have the same skeleton. It is used for uniqueness and lookup.
+func Skeleton(username string) string {
+	return strings.Map(func(r rune) rune {
+		if c, ok := confusables[r]; ok {
+			return c
+		}
+		return r
+	}, Canonical(username))
+}
+
+// Validate checks that the username can be displayed and compared unambiguously.
+func Validate(username string) error {
+	if username == "" {
+		return ErrEmpty
+	}
+	if utf8.RuneCountInString(username) > MaxLength {
+		return ErrTooLong
+	}
+	script := ""
+	for _, r := range Canonical(username) {
+		if r == '/' || unicode.IsSpace(r) || unicode.IsControl(r) || unicode.Is(unicode.Cf, r) || r == utf8.RuneError {
+			return ErrInvalidChars
+		}
+		for name, table := range scripts {
+			if unicode.Is(table, r) {
+				if script != "" && script != name {
+					return ErrMixedScripts
+				}
+				script = name
+			}
+		}
+	}
+	return nil
+}
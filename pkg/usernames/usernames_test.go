@@ -0,0 +1,97 @@
+package usernames
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		username string
+		want     string
+	}{
+		{"IvanIvanov2000", "ivanivanov2000"},
+		{"ＩｖａｎＩｖａｎｏｖ２０００", "ivanivanov2000"}, // fullwidth
+		{"ИванИванов", "иваниванов"},
+		{"Straße", "strasse"},
+		{"ﬁle", "file"},        // ligature
+		{"José", "josé"},      // combining accent is composed
+		{"ΣΊΣΥΦΟΣ", "σίσυφοσ"}, // final sigma is folded as well
+		{"user10", "user10"},
+	}
+	for _, tt := range tests {
+		if got := Canonical(tt.username); got != tt.want {
+			t.Errorf("Canonical(%q) = %q, want %q", tt.username, got, tt.want)
+		}
+	}
+}
+
+func TestSkeleton(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{name: "case", a: "IvanIvanov2000", b: "ivanivanov2000", same: true},
+		{name: "fullwidth", a: "ＩｖａｎＩｖａｎｏｖ２０００", b: "IvanIvanov2000", same: true},
+		{name: "cyrillic a", a: "аdmin", b: "admin", same: true},
+		{name: "all cyrillic lookalikes", a: "рауреаl", b: "paypeal", same: true},
+		{name: "greek omicron", a: "gοοgle", b: "google", same: true},
+		{name: "armenian o", a: "jօhn", b: "john", same: true},
+		{name: "dotless i", a: "ıvan", b: "ivan", same: true},
+		{name: "script g", a: "ɡeorge", b: "george", same: true},
+		{name: "cyrillic capital", a: "АDMIN", b: "admin", same: true},
+		{name: "zero and o", a: "user10", b: "userlo"},
+		{name: "one and l", a: "ivan1", b: "ivanl"},
+		{name: "zero and letter o", a: "g00gle", b: "google"},
+		{name: "different names", a: "IvanIvanov2000", b: "PetrPetrov1990"},
+		{name: "digits", a: "user1", b: "user2"},
+		{name: "cyrillic without lookalikes", a: "иван", b: "ivan"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := Skeleton(tt.a), Skeleton(tt.b)
+			if (a == b) != tt.same {
+				t.Errorf("Skeleton(%q) = %q, Skeleton(%q) = %q, want same = %t", tt.a, a, tt.b, b, tt.same)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		wantErr  error
+	}{
+		{name: "latin", username: "IvanIvanov2000"},
+		{name: "punctuation", username: "ivan_ivanov-2000.ok"},
+		{name: "cyrillic", username: "ИванИванов2000"},
+		{name: "greek", username: "αβγ"},
+		{name: "unrestricted script with latin", username: "ivan用户"},
+		{name: "max length", username: strings.Repeat("a", MaxLength)},
+		{name: "max length in characters", username: strings.Repeat("я", MaxLength)},
+		{name: "empty", username: "", wantErr: ErrEmpty},
+		{name: "too long", username: strings.Repeat("a", MaxLength+1), wantErr: ErrTooLong},
+		{name: "space", username: "ivan ivanov", wantErr: ErrInvalidChars},
+		{name: "no-break space", username: "ivan\u00a0ivanov", wantErr: ErrInvalidChars},
+		{name: "tab", username: "ivan\tivanov", wantErr: ErrInvalidChars},
+		{name: "slash", username: "ivan/ivanov", wantErr: ErrInvalidChars},
+		{name: "fullwidth slash", username: "ivan／ivanov", wantErr: ErrInvalidChars},
+		{name: "zero width space", username: "ivan\u200bivanov", wantErr: ErrInvalidChars},
+		{name: "right-to-left override", username: "ivan\u202eivanov", wantErr: ErrInvalidChars},
+		{name: "control", username: "ivan\x00", wantErr: ErrInvalidChars},
+		{name: "invalid utf-8", username: "ivan\xff", wantErr: ErrInvalidChars},
+		{name: "latin and cyrillic", username: "аdmin", wantErr: ErrMixedScripts},
+		{name: "latin and greek", username: "gοogle", wantErr: ErrMixedScripts},
+		{name: "cyrillic and armenian", username: "иванօ", wantErr: ErrMixedScripts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.username); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate(%q) = %v, want %v", tt.username, err, tt.wantErr)
+			}
+		})
+	}
+}